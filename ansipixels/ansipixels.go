@@ -4,14 +4,18 @@ package ansipixels
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"image/color"
 	"io"
+	"math"
 	"os"
 	"os/signal"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"fortio.org/log"
@@ -30,28 +34,79 @@ type AnsiPixels struct {
 	In            *os.File
 	InWithTimeout *terminal.TimeoutReader
 	state         *term.State
+	logSink       io.Writer
 	buf           [bufSize]byte
 	Data          []byte
 	W, H          int  // Width and Height
 	x, y          int  // Cursor last set position
+	sx, sy        int  // Cursor position saved by SaveCursorPos/SaveScreen
+	cellW, cellH  int  // Character cell size in pixels, once queried (ReadCellSize)
 	Mouse         bool // Mouse event received
 	Mx, My        int  // Mouse last known position
 	Mbuttons      int  // Mouse buttons and modifier state
+	mouseMode     MouseMode
+	mouseStack    []MouseMode
+	drag          dragState
+	frames        *frameStats // for the stats overlay, see RecordFrame.
+	throttle      *throttledWriter
+	background    *color.RGBA // Terminal background color, once queried.
+	snapshot      *screenBuffer
+	input         io.Reader        // see SetInput.
+	pendingIn     []byte           // incomplete sequence for ReadEvents.
+	pendingEv     []terminal.Event // decoded but not yet returned by ReadEvent.
+	ellipsis      *string          // see SetEllipsis, nil for the default.
+	mu            sync.Mutex       // see Lock/Do.
+	cursorHidden  bool             // to restore it after a stop/continue.
+	cursorStyle   CursorStyle      // see SetCursorStyle.
+	noAutoFlush   bool             // see SetAutoFlush.
+	lastActive    time.Time        // last input, resize or Active() call, for IdleFPS.
+	idle          bool             // running at IdleFPS.
+	inAltScreen   bool             // see EnterAltScreen.
+	kittyShown    bool             // see DeleteKittyImage.
+	dbuf          *doubleBuffer    // see SetDoubleBuffer, nil when off.
+	scrollRegion  bool             // see SetScrollRegion.
+	cellErr       error            // ReadCellSize error, if it failed.
 	C             chan os.Signal
 	// Should image be monochrome, 256 or true color
 	TrueColor bool
+	Sixel     bool         // Sixel graphics images (takes precedence over the other modes, see DetectSixel)
 	Color     bool         // 256 (216) color mode
 	Gray      bool         // grayscale mode
 	Margin    int          // Margin around the image (image is smaller by 2*margin)
 	FPS       float64      // (Target) Frames per second used for Reading with timeout
 	OnResize  func() error // Callback when terminal is resized
+	// Color used to fill the area not covered by the image (letterbox bars), default (zero value) is black.
+	LetterboxColor color.RGBA
+	// Key that forces a full redraw (clear screen and OnResize) when read alone, for instance 12
+	// (^L, the de facto standard) to recover from terminal corruption. 0 (default) disables it.
+	RedrawKey byte
+	// When set, ClearScreen erases line by line instead of using \033[2J which, in some terminals,
+	// pushes the screen content into the scrollback (unwanted in alternate screen mode for instance).
+	ClearInPlace bool
+	// When set, Open switches to the alternate screen buffer (see [EnterAltScreen]) and Restore
+	// switches back, so the original screen content and cursor position are preserved.
+	UseAltScreen bool
+	// Kitty graphics protocol images (takes precedence over the other modes, including Sixel, see
+	// DetectKittyGraphics).
+	KittyGraphics bool
+	// When > 0, the read timeout switches to IdleFPS after IdleAfter (default 2 seconds) without
+	// input, resize or [Active] calls, and back to FPS as soon as one happens. Saves CPU for mostly
+	// static displays; animating apps should call Active for each frame they want at full rate.
+	IdleFPS   float64
+	IdleAfter time.Duration
 }
 
 func NewAnsiPixels(fps float64) *AnsiPixels {
+	return NewAnsiPixelsWithBuffer(fps, 0)
+}
+
+// NewAnsiPixelsWithBuffer is like [NewAnsiPixels] with a size (in bytes) for the Out buffer,
+// e.g. larger to write big frames (images) with fewer system calls. 0 uses the default size.
+func NewAnsiPixelsWithBuffer(fps float64, size int) *AnsiPixels {
 	ap := &AnsiPixels{
 		FdIn:          safecast.MustConvert[int](os.Stdin.Fd()),
 		fdOut:         safecast.MustConvert[int](os.Stdout.Fd()),
-		Out:           bufio.NewWriter(os.Stdout),
+		Out:           bufio.NewWriterSize(os.Stdout, size),
 		In:            os.Stdin,
 		FPS:           fps,
 		InWithTimeout: terminal.NewTimeoutReader(os.Stdin, time.Duration(1e9/fps)),
@@ -65,12 +120,68 @@ func (ap *AnsiPixels) ChangeFPS(fps float64) {
 	ap.InWithTimeout.ChangeTimeout(1 * time.Second / time.Duration(fps))
 }
 
+// Active marks the app as active (e.g. animating): if [IdleFPS] is set and it was idle, the
+// full FPS read timeout is restored.
+func (ap *AnsiPixels) Active() {
+	ap.lastActive = time.Now()
+	if ap.idle {
+		ap.idle = false
+		ap.ChangeFPS(ap.FPS)
+	}
+}
+
+// Switches to IdleFPS if it's set and there was no activity for IdleAfter.
+func (ap *AnsiPixels) checkIdle() {
+	if ap.IdleFPS <= 0 || ap.idle {
+		return
+	}
+	if ap.lastActive.IsZero() {
+		ap.lastActive = time.Now()
+		return
+	}
+	after := ap.IdleAfter
+	if after <= 0 {
+		after = 2 * time.Second
+	}
+	if time.Since(ap.lastActive) >= after {
+		ap.idle = true
+		ap.ChangeFPS(ap.IdleFPS)
+	}
+}
+
+// Open puts the terminal in raw mode and gets its size. If stdout isn't a terminal (e.g. piped or
+// in CI), it switches to [SnapshotMode] instead of failing.
 func (ap *AnsiPixels) Open() (err error) {
+	if !term.IsTerminal(ap.fdOut) {
+		return ap.openSnapshot()
+	}
 	ap.state, err = term.MakeRaw(ap.FdIn)
-	if err == nil {
-		err = ap.GetSize()
+	if err != nil {
+		return
 	}
-	return
+	if ap.UseAltScreen {
+		ap.EnterAltScreen()
+	}
+	return ap.GetSize()
+}
+
+// EnterAltScreen switches to the (xterm) alternate screen buffer, saving the cursor position. The
+// main screen content is left untouched, including by [ClearScreen]'s \033[2J (which, in some
+// terminals, still pushes the alternate screen content into the scrollback, see [ClearInPlace]),
+// until [LeaveAltScreen].
+func (ap *AnsiPixels) EnterAltScreen() {
+	ap.WriteString("\033[?1049h")
+	ap.inAltScreen = true
+}
+
+// LeaveAltScreen switches back to the main screen, as it was before [EnterAltScreen], and
+// restores the cursor position. Does nothing if not in the alternate screen.
+func (ap *AnsiPixels) LeaveAltScreen() {
+	if !ap.inAltScreen {
+		return
+	}
+	ap.WriteString("\033[?1049l")
+	ap.inAltScreen = false
 }
 
 // So this handles both outgoing and incoming escape sequences, but maybe we should split them
@@ -146,6 +257,7 @@ func (ap *AnsiPixels) HandleSignal(s os.Signal) error {
 	if !ap.IsResizeSignal(s) {
 		return terminal.ErrSignal
 	}
+	ap.resumeAfterStop(s)
 	err := ap.GetSize()
 	if err != nil {
 		return err
@@ -162,7 +274,7 @@ func (ap *AnsiPixels) HandleSignal(s os.Signal) error {
 // will automatically call OnResize if set and if a resize signal is received and continue trying
 // to read.
 func (ap *AnsiPixels) ReadOrResizeOrSignal() error {
-	ap.EndSyncMode()
+	ap.Do(ap.EndSyncMode)
 	for {
 		n, err := ap.ReadOrResizeOrSignalOnce()
 		if err != nil {
@@ -179,39 +291,141 @@ func (ap *AnsiPixels) ReadOrResizeOrSignal() error {
 func (ap *AnsiPixels) ReadOrResizeOrSignalOnce() (int, error) {
 	select {
 	case s := <-ap.C:
+		ap.Active()
 		err := ap.HandleSignal(s)
 		if err != nil {
 			return 0, err
 		}
 	default:
-		n, err := ap.InWithTimeout.Read(ap.buf[0:bufSize])
+		n, err := ap.readInput()
+		if n > 0 {
+			ap.Active()
+		} else {
+			ap.checkIdle()
+		}
 		ap.Data = ap.buf[0:n]
 		ap.MouseDecode()
+		if n == 1 && ap.RedrawKey != 0 && ap.Data[0] == ap.RedrawKey && ap.OnResize != nil {
+			ap.ClearScreen()
+			rerr := ap.OnResize()
+			ap.EndSyncMode()
+			ap.Data = ap.Data[:0]
+			return 0, rerr
+		}
 		return n, err
 	}
 	return 0, nil
 }
 
+// Reads (with timeout) into ap.buf from the terminal or the SetInput reader.
+func (ap *AnsiPixels) readInput() (int, error) {
+	if ap.input != nil {
+		return ap.input.Read(ap.buf[0:bufSize])
+	}
+	return ap.InWithTimeout.Read(ap.buf[0:bufSize])
+}
+
+// SetInput makes ReadOrResizeOrSignal[Once] read from r instead of the terminal, for instance
+// a [terminal.ScriptedReader] to replay keys and mouse events. nil restores terminal input.
+// Terminal queries (e.g. ReadCursorPos) still use the terminal.
+func (ap *AnsiPixels) SetInput(r io.Reader) {
+	ap.input = r
+}
+
+// Lock serializes drawing from multiple goroutines (e.g. a background goroutine updating a
+// clock): AnsiPixels isn't otherwise safe for concurrent use. Once a second goroutine draws,
+// all drawing (including EndSyncMode and OnResize) should happen between Lock and Unlock,
+// or through [Do]. ReadOrResizeOrSignal and ReadEvents take the lock for their initial flush
+// so it must not be held while reading.
+func (ap *AnsiPixels) Lock() {
+	ap.mu.Lock()
+}
+
+// Unlock releases the lock taken by [Lock].
+func (ap *AnsiPixels) Unlock() {
+	ap.mu.Unlock()
+}
+
+// Do runs fn with the drawing lock held (see [Lock]).
+func (ap *AnsiPixels) Do(fn func()) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	fn()
+}
+
 func (ap *AnsiPixels) StartSyncMode() {
+	if ap.throttle != nil {
+		_ = ap.Out.Flush()
+		ap.throttle.inFrame = true
+	}
 	ap.WriteString("\033[?2026h")
 }
 
-// End sync (and flush).
+// SetAutoFlush sets whether [EndSyncMode] (also called by the Read functions) flushes the
+// output, which is the default. When off, the caller is responsible for calling ap.Out.Flush()
+// (for instance once per frame, after all the drawing). Frames are still flushed when
+// [SetMaxBytesPerSecond] throttling is on.
+func (ap *AnsiPixels) SetAutoFlush(enabled bool) {
+	ap.noAutoFlush = !enabled
+}
+
+// End sync (and flush, or drop the frame if over the [SetMaxBytesPerSecond] budget).
 func (ap *AnsiPixels) EndSyncMode() {
+	if ap.dbuf != nil {
+		if err := ap.Present(); err != nil {
+			log.Errf("Error writing frame: %v", err)
+		}
+		return
+	}
 	ap.WriteString("\033[?2026l")
-	_ = ap.Out.Flush()
+	if !ap.noAutoFlush || ap.throttle != nil {
+		_ = ap.Out.Flush()
+	}
+	if ap.throttle != nil {
+		if err := ap.throttle.endFrame(); err != nil {
+			log.Errf("Error writing frame: %v", err)
+		}
+	}
 }
 
 func (ap *AnsiPixels) GetSize() (err error) {
+	if ap.snapshot != nil {
+		return nil // fixed size.
+	}
+	w, h := ap.W, ap.H
 	ap.W, ap.H, err = term.GetSize(ap.fdOut)
+	if (ap.W != w || ap.H != h) && ap.dbuf != nil {
+		ap.resetDoubleBuffer()
+	}
 	return
 }
 
 func (ap *AnsiPixels) Restore() {
+	if ap.snapshot != nil {
+		_, _ = os.Stdout.WriteString(ap.Snapshot())
+		ap.snapshot = nil
+		ap.Out.Reset(os.Stdout)
+		if ap.state != nil {
+			_ = term.Restore(ap.FdIn, ap.state)
+			ap.state = nil
+		}
+		return
+	}
 	if ap.state == nil {
 		return
 	}
+	ap.SetMouseMode(NoMouse) // turn off any mouse mode left enabled.
+	if ap.throttle != nil {
+		ap.SetMaxBytesPerSecond(0) // sends any pending output.
+	}
 	ap.ShowCursor()
+	if ap.cursorStyle != DefaultCursor {
+		ap.SetCursorStyle(DefaultCursor)
+	}
+	if ap.scrollRegion {
+		ap.ResetScrollRegion()
+	}
+	ap.LeaveAltScreen()
 	ap.EndSyncMode()
 	err := term.Restore(ap.FdIn, ap.state)
 	if err != nil {
@@ -220,7 +434,17 @@ func (ap *AnsiPixels) Restore() {
 	ap.state = nil
 }
 
+// ClearScreen clears the whole screen, without moving the cursor. See [ClearInPlace].
 func (ap *AnsiPixels) ClearScreen() {
+	if ap.ClearInPlace {
+		x, y := ap.x, ap.y
+		for i := range ap.H {
+			ap.MoveCursor(0, i)
+			ap.WriteString("\033[2K")
+		}
+		ap.MoveCursor(x, y)
+		return
+	}
 	_, err := ap.Out.WriteString("\033[2J")
 	if err != nil {
 		log.Errf("Error clearing screen: %v", err)
@@ -267,29 +491,79 @@ func (ap *AnsiPixels) ScreenWidth(str string) int {
 	return uniseg.StringWidth(string(b))
 }
 
+// EnsureMinSize returns true if the terminal is at least w x h. Otherwise the screen is cleared
+// and a "too small" message is shown: the caller should skip drawing (and layout computations
+// that assume a minimum size) until the next resize, e.g. at the start of OnResize.
+// The drawing primitives clip rather than fail on small sizes but the result would be garbled.
+func (ap *AnsiPixels) EnsureMinSize(w, h int) bool {
+	if ap.W >= w && ap.H >= h {
+		return true
+	}
+	ap.ClearScreen()
+	ap.WriteCentered(ap.H/2, "Terminal too small: %dx%d, need %dx%d", ap.W, ap.H, w, h)
+	return false
+}
+
+// WriteCentered writes msg centered horizontally on line y. Messages wider than the screen
+// are truncated with an ellipsis (see [TruncateRightToFit]).
 func (ap *AnsiPixels) WriteCentered(y int, msg string, args ...interface{}) {
 	s := fmt.Sprintf(msg, args...)
-	x := (ap.W - ap.ScreenWidth(s)) / 2
+	s, w := ap.TruncateRightToFit(s, ap.W)
+	x := (ap.W - w) / 2
 	ap.MoveCursor(x, y)
 	ap.WriteString(s)
 }
 
+// TruncateRightToFit returns msg, if it fits in maxWidth, or its beginning followed by the
+// [Ellipsis] so it does, and its resulting width. Ansi sequences are kept.
+func (ap *AnsiPixels) TruncateRightToFit(msg string, maxWidth int) (string, int) {
+	w := ap.ScreenWidth(msg)
+	if w <= maxWidth {
+		return msg, w
+	}
+	e := ap.Ellipsis()
+	ew := ap.ScreenWidth(e)
+	if maxWidth < ew {
+		return "", 0
+	}
+	s := clipColumns(msg, 0, maxWidth-ew)
+	return s + e, ap.ScreenWidth(s) + ew
+}
+
 func (ap *AnsiPixels) TruncateLeftToFit(msg string, maxWidth int) (string, int) {
 	w := ap.ScreenWidth(msg)
 	if w < maxWidth {
 		return msg, w
 	}
 	// slow path.
-	str := "…"
+	str := ap.Ellipsis()
+	ew := ap.ScreenWidth(str)
 	runes := []rune(msg)
 	// This isn't optimized and also because of AnsiClean behind the scene we might remove codes we should keep.
 	for i := range runes {
 		w = ap.ScreenWidth(string(runes[i:]))
-		if w < maxWidth {
-			return str + string(runes[i:]), w + 1
+		if w+ew <= maxWidth {
+			return str + string(runes[i:]), w + ew
 		}
 	}
-	return str, 1
+	if ew > maxWidth {
+		return "", 0
+	}
+	return str, ew
+}
+
+// SetEllipsis sets the indicator used by the truncation functions (e.g. [TruncateLeftToFit]),
+// "…" by default. Can be set to "..." for fonts rendering "…" poorly, or "" for none.
+func (ap *AnsiPixels) SetEllipsis(s string) {
+	ap.ellipsis = &s
+}
+
+// Ellipsis returns the truncation indicator, see [SetEllipsis].
+func (ap *AnsiPixels) Ellipsis() string {
+	if ap.ellipsis == nil {
+		return "…"
+	}
+	return *ap.ellipsis
 }
 
 func (ap *AnsiPixels) WriteRight(y int, msg string, args ...interface{}) {
@@ -307,41 +581,118 @@ func (ap *AnsiPixels) ClearEndOfLine() {
 	ap.WriteString("\033[K")
 }
 
+// CopyToClipboard asks the terminal to put text in the system clipboard (using OSC 52,
+// not all terminals support it or have it enabled).
+func (ap *AnsiPixels) CopyToClipboard(text string) {
+	ap.WriteString("\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a")
+}
+
+// SetWindowTitle sets the terminal window (or tab) title (using OSC 2). Control characters are
+// removed from title so it can't end the sequence early.
+func (ap *AnsiPixels) SetWindowTitle(title string) {
+	ap.WriteString("\033]2;" + stripControls(title) + "\a")
+}
+
+// SetIconName sets the terminal icon name (using OSC 1), which some terminals show as the tab
+// title. Control characters are removed from name.
+func (ap *AnsiPixels) SetIconName(name string) {
+	ap.WriteString("\033]1;" + stripControls(name) + "\a")
+}
+
+// PushTitle saves the current window title and icon name on the terminal's stack (xterm
+// XTWINOPS 22, ignored by terminals not supporting it), to be restored by [PopTitle], e.g. on exit
+// after changing them with [SetWindowTitle].
+func (ap *AnsiPixels) PushTitle() {
+	ap.WriteString("\033[22;0t")
+}
+
+// PopTitle restores the window title and icon name saved by [PushTitle].
+func (ap *AnsiPixels) PopTitle() {
+	ap.WriteString("\033[23;0t")
+}
+
+// Returns s without its (C0, DEL and C1) control characters.
+func stripControls(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
 var cursPosRegexp = regexp.MustCompile(`^(.*)\033\[(\d+);(\d+)R(.*)$`)
 
 // This also synchronizes the display and ends the syncmode.
 func (ap *AnsiPixels) ReadCursorPos() (int, int, error) {
-	x := -1
-	y := -1
-	reqPosStr := "\033[?2026l\033[6n" // also ends sync mode
-	n, err := ap.Out.WriteString(reqPosStr)
+	return ap.queryTwoInts("\033[6n", cursPosRegexp, "cursor position")
+}
+
+// Sends the request (after ending sync mode) and reads until the response matching the regexp
+// (with 4 groups: before, 2 integers, after) is found. Data before and after the response
+// is left in ap.Data.
+func (ap *AnsiPixels) queryTwoInts(request string, re *regexp.Regexp, what string) (int, int, error) {
+	res, err := ap.query(ap.In, request, re, what)
+	if res == nil {
+		return -1, -1, err
+	}
+	x, err := strconv.Atoi(string(res[2]))
+	if err != nil {
+		return x, -1, err
+	}
+	y, err := strconv.Atoi(string(res[3]))
+	return x, y, err
+}
+
+// Sends the request (after ending sync mode) and reads from in until the response matching the
+// regexp (whose first and last groups are the data before and after the response) is found, which
+// is returned as submatches. Data before and after the response is left in ap.Data. A read of 0
+// bytes (e.g. a timeout of in) is an error, as is [SnapshotMode] (no terminal to answer).
+func (ap *AnsiPixels) query(in io.Reader, request string, re *regexp.Regexp, what string) ([][]byte, error) {
+	out := ap.Out
+	switch {
+	case ap.snapshot != nil:
+		return nil, errSnapshotMode
+	case ap.dbuf != nil:
+		out = ap.dbuf.out // not into the screen buffer.
+	case ap.throttle != nil:
+		// The frame ends with the sync mode, the request must not be held (or dropped) with it.
+		_ = ap.Out.Flush()
+		if err := ap.throttle.endFrame(); err != nil {
+			return nil, err
+		}
+		out = ap.throttle.out
+	}
+	reqStr := "\033[?2026l" + request // also ends sync mode
+	n, err := out.WriteString(reqStr)
 	if err != nil {
-		return x, y, err
+		return nil, err
 	}
-	if n != len(reqPosStr) {
-		return x, y, errors.New("short write")
+	if n != len(reqStr) {
+		return nil, errors.New("short write")
 	}
-	err = ap.Out.Flush()
+	err = out.Flush()
 	if err != nil {
-		return x, y, err
+		return nil, err
 	}
 	i := 0
 	ap.Data = nil
+	var res [][]byte
 	for {
 		if i == bufSize {
-			return x, y, errors.New("buffer full, no cursor position found")
+			return nil, errors.New("buffer full, no " + what + " found")
 		}
-		n, err = ap.In.Read(ap.buf[i:bufSize])
+		n, err = in.Read(ap.buf[i:bufSize])
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			return x, y, err
+			return nil, err
 		}
 		if n == 0 {
-			return x, y, errors.New("no data read from cursor position")
+			return nil, errors.New("no data read from " + what)
 		}
-		res := cursPosRegexp.FindSubmatch(ap.buf[0 : i+n])
+		res = re.FindSubmatch(ap.buf[0 : i+n])
 		if log.LogVerbose() {
 			// use go run . -loglevel verbose 2> /tmp/ansipixels.log to capture this
 			log.LogVf("Last buffer read: [%q] -> [%q] regexp match %t", ap.buf[i:i+n], ap.buf[0:i+n], res != nil)
@@ -351,28 +702,116 @@ func (ap *AnsiPixels) ReadCursorPos() (int, int, error) {
 			i += n
 			continue
 		}
-		x, err = strconv.Atoi(string(res[2]))
-		if err != nil {
-			return x, y, err
-		}
-		y, err = strconv.Atoi(string(res[3]))
-		if err != nil {
-			return x, y, err
-		}
 		ap.Data = append(ap.Data, res[1]...)
-		ap.Data = append(ap.Data, res[4]...)
+		ap.Data = append(ap.Data, res[len(res)-1]...)
 		break
 	}
 	ap.MouseDecode()
-	return x, y, err
+	return res, err
+}
+
+var bgColorRegexp = regexp.MustCompile(`\033\]11;rgb:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})(\033\\|\a)`)
+
+// QueryBackgroundColor asks the terminal for its background color (using OSC 11). A cursor
+// position request is sent right after so terminals not supporting OSC 11 return an error
+// instead of blocking. Like ReadCursorPos, this also synchronizes the display and ends the syncmode.
+func (ap *AnsiPixels) QueryBackgroundColor() (color.RGBA, error) {
+	_, _, err := ap.queryTwoInts("\033]11;?\033\\\033[6n", cursPosRegexp, "background color")
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	res := bgColorRegexp.FindSubmatchIndex(ap.Data)
+	if res == nil {
+		return color.RGBA{}, errors.New("no background color response (OSC 11 not supported)")
+	}
+	var c [3]uint8
+	for i := range c {
+		hex := ap.Data[res[2+2*i]:res[3+2*i]]
+		v, _ := strconv.ParseUint(string(hex), 16, 16) // can't fail given the regexp.
+		// 1 to 4 hex digits, scale to 8 bits.
+		c[i] = safecast.MustConvert[uint8](v * 255 / (uint64(1)<<(4*len(hex)) - 1))
+	}
+	ap.Data = append(ap.Data[:res[0]], ap.Data[res[1]:]...)
+	ap.background = &color.RGBA{R: c[0], G: c[1], B: c[2], A: 255}
+	return *ap.background, nil
+}
+
+// IsDarkBackground returns true if the terminal background color is dark (relative luminance
+// below 50%), e.g. to pick a dark or light theme. The background color is queried once (see
+// [QueryBackgroundColor]); if that fails, dark is assumed as it's the most common.
+func (ap *AnsiPixels) IsDarkBackground() bool {
+	bg := ap.backgroundColor()
+	return 0.2126*float64(bg.R)+0.7152*float64(bg.G)+0.0722*float64(bg.B) < 128
+}
+
+// Returns the terminal background color, queried once, black if that fails.
+func (ap *AnsiPixels) backgroundColor() color.RGBA {
+	if ap.background == nil {
+		if _, err := ap.QueryBackgroundColor(); err != nil {
+			log.Warnf("Unable to get background color, assuming dark: %v", err)
+			ap.background = &color.RGBA{A: 255}
+		}
+	}
+	return *ap.background
+}
+
+// SaveCursorPos saves the cursor position (and attributes), to be restored by RestoreCursorPos.
+func (ap *AnsiPixels) SaveCursorPos() {
+	ap.WriteString("\0337")
+	ap.sx, ap.sy = ap.x, ap.y
+}
+
+// RestoreCursorPos restores the cursor position saved by SaveCursorPos.
+func (ap *AnsiPixels) RestoreCursorPos() {
+	ap.WriteString("\0338")
+	ap.x, ap.y = ap.sx, ap.sy
+}
+
+// SaveScreen saves the cursor (DECSET 1048) and switches to the alternate screen (DECSET 1047),
+// which is then cleared. RestoreScreen brings back the original screen and cursor exactly as they
+// were, e.g. after a full screen modal dialog or help page, without needing to redraw it.
+// Not for apps already using the alternate screen.
+func (ap *AnsiPixels) SaveScreen() {
+	ap.WriteString("\033[?1048h\033[?1047h")
+	ap.sx, ap.sy = ap.x, ap.y
+	ap.ClearScreen()
+}
+
+// RestoreScreen switches back from the alternate screen and restores the cursor saved by SaveScreen.
+func (ap *AnsiPixels) RestoreScreen() {
+	ap.WriteString("\033[?1047l\033[?1048l")
+	ap.x, ap.y = ap.sx, ap.sy
 }
 
 func (ap *AnsiPixels) HideCursor() {
 	ap.WriteString("\033[?25l") // hide cursor
+	ap.cursorHidden = true
 }
 
 func (ap *AnsiPixels) ShowCursor() {
 	ap.WriteString("\033[?25h") // show cursor
+	ap.cursorHidden = false
+}
+
+// After a SIGCONT (job control continue), re-asserts the raw mode, the cursor visibility and style
+// and the mouse modes as the terminal may have been reset while stopped.
+func (ap *AnsiPixels) resumeAfterStop(s os.Signal) {
+	if !isContinueSignal(s) || ap.state == nil {
+		return
+	}
+	log.LogVf("Continue signal received, restoring terminal modes")
+	if _, err := term.MakeRaw(ap.FdIn); err != nil { // keeping the original state for Restore.
+		log.Errf("Error setting raw mode again: %v", err)
+	}
+	if ap.cursorHidden {
+		ap.HideCursor()
+	}
+	if ap.cursorStyle != DefaultCursor {
+		ap.SetCursorStyle(ap.cursorStyle)
+	}
+	mode := ap.mouseMode
+	ap.mouseMode = NoMouse
+	ap.SetMouseMode(mode)
 }
 
 func (ap *AnsiPixels) DrawSquareBox(x, y, w, h int) {
@@ -383,7 +822,142 @@ func (ap *AnsiPixels) DrawRoundBox(x, y, w, h int) {
 	ap.DrawBox(x, y, w, h, RoundTopLeft, RoundTopRight, RoundBottomLeft, RoundBottomRight)
 }
 
+// DrawSquareBoxC draws a square box with the given color and resets the color after.
+// color is any ansi color/attributes sequence, e.g. [Red] or [Orange]+[Bold].
+func (ap *AnsiPixels) DrawSquareBoxC(x, y, w, h int, color string) {
+	ap.WriteString(color)
+	ap.DrawSquareBox(x, y, w, h)
+	ap.WriteString(Reset)
+}
+
+// DrawRoundBoxC draws a round corners box with the given color and resets the color after.
+func (ap *AnsiPixels) DrawRoundBoxC(x, y, w, h int, color string) {
+	ap.WriteString(color)
+	ap.DrawRoundBox(x, y, w, h)
+	ap.WriteString(Reset)
+}
+
+// DrawHLine draws a horizontal line of length times r (e.g. '─') starting at x, y,
+// clipped to the screen.
+func (ap *AnsiPixels) DrawHLine(x, y, length int, r rune) {
+	if y < 0 || y >= ap.H {
+		return
+	}
+	end := min(x+length, ap.W)
+	x = max(x, 0)
+	if end <= x {
+		return
+	}
+	ap.MoveCursor(x, y)
+	ap.WriteString(strings.Repeat(string(r), end-x))
+}
+
+// DrawVLine draws a vertical line of length times r (e.g. '│') from x, y going down,
+// clipped to the screen.
+func (ap *AnsiPixels) DrawVLine(x, y, length int, r rune) {
+	if x < 0 || x >= ap.W {
+		return
+	}
+	end := min(y+length, ap.H)
+	for i := max(y, 0); i < end; i++ {
+		ap.MoveCursor(x, i)
+		ap.WriteRune(r)
+	}
+}
+
+// FillRect paints a solid bg color rectangle (using spaces), clipped to the screen.
+// Uses true color if [TrueColor] is set, the closest of the 216 colors otherwise.
+func (ap *AnsiPixels) FillRect(x, y, w, h int, bg color.RGBA) {
+	endX := min(x+w, ap.W)
+	endY := min(y+h, ap.H)
+	x = max(x, 0)
+	if endX <= x {
+		return
+	}
+	spaces := strings.Repeat(" ", endX-x)
+	ap.WriteString(ap.bgSequence(bg))
+	// Background color stays set across cursor moves so it's only needed once.
+	for i := max(y, 0); i < endY; i++ {
+		ap.MoveCursor(x, i)
+		ap.WriteString(spaces)
+	}
+	ap.WriteString(Reset)
+}
+
+// EraseRect erases (with the current background color) the w x h rectangle at x, y,
+// clipped to the screen, e.g. to remove an overlay without clearing the whole screen.
+func (ap *AnsiPixels) EraseRect(x, y, w, h int) {
+	endX := min(x+w, ap.W)
+	endY := min(y+h, ap.H)
+	x = max(x, 0)
+	if endX <= x {
+		return
+	}
+	ech := fmt.Sprintf("\033[%dX", endX-x) // Erase characters, doesn't move the cursor.
+	for i := max(y, 0); i < endY; i++ {
+		ap.MoveCursor(x, i)
+		ap.WriteString(ech)
+	}
+}
+
+// RestoreOnPanic restores the terminal (see [Restore]) if the current goroutine is panicking
+// and then re-panics, so the panic message and stack trace are readable instead of printed in raw
+// mode on a terminal left unusable. Must be deferred directly, right after Open:
+//
+//	defer ap.RestoreOnPanic()
+func (ap *AnsiPixels) RestoreOnPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	ap.MoveCursor(0, ap.H-1)
+	ap.Restore()
+	fmt.Fprint(os.Stderr, "\r\n")
+	panic(r)
+}
+
+// Returns the sequence to set the background color: true color if [TrueColor] is set,
+// the closest of the 216 colors otherwise.
+func (ap *AnsiPixels) bgSequence(bg color.RGBA) string {
+	if ap.TrueColor {
+		return fmt.Sprintf("\033[48;2;%d;%d;%dm", bg.R, bg.G, bg.B)
+	}
+	return fmt.Sprintf("\033[48;5;%dm", convertColorTo216(bg))
+}
+
+// DrawPanel fills the rectangle with the bg color and draws a round corners border around its
+// edge, with, if shadow is true, a drop shadow one cell down and to the right. The shadow is a
+// darker bg blended with the terminal background, which isn't queried while drawing: call
+// [QueryBackgroundColor] during setup (e.g. right after Open), black is used otherwise.
+func (ap *AnsiPixels) DrawPanel(x, y, w, h int, bg color.RGBA, shadow bool) {
+	if shadow {
+		dark := color.RGBA{R: bg.R / 2, G: bg.G / 2, B: bg.B / 2, A: 255}
+		var termBg color.RGBA
+		if ap.background != nil {
+			termBg = *ap.background
+		}
+		sc := blend(termBg, dark, 0.5)
+		ap.FillRect(x+1, y+h, w, 1, sc)
+		ap.FillRect(x+w, y+1, 1, h, sc)
+	}
+	ap.FillRect(x, y, w, h, bg)
+	ap.WriteString(ap.bgSequence(bg))
+	ap.DrawRoundBox(x, y, w, h)
+	ap.WriteString(Reset)
+}
+
+// Returns a mix of a and b: t = 0 is a, t = 1 is b.
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(ca, cb uint8) uint8 {
+		return safecast.MustConvert[uint8](math.Round(float64(ca)*(1-t) + float64(cb)*t))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
 func (ap *AnsiPixels) DrawBox(x, y, w, h int, topLeft, topRight, bottomLeft, bottomRight string) {
+	if w < 2 || h < 2 {
+		return // too small to draw (e.g. tiny terminal).
+	}
 	if y >= 0 {
 		ap.MoveCursor(x, y)
 		ap.WriteString(topLeft)
@@ -406,15 +980,17 @@ func (ap *AnsiPixels) DrawBox(x, y, w, h int, topLeft, topRight, bottomLeft, bot
 	}
 	ap.MoveCursor(x, y+h-1)
 	ap.WriteString(bottomLeft)
-	ap.WriteString(strings.Repeat(Horizontal, w-3))
 	if x+w <= ap.W {
-		ap.WriteString(Horizontal + bottomRight)
+		ap.WriteString(strings.Repeat(Horizontal, w-2) + bottomRight)
 	} else {
-		ap.WriteString(topRight)
+		ap.WriteString(strings.Repeat(Horizontal, max(0, w-3)) + topRight)
 	}
 }
 
-func (ap *AnsiPixels) WriteBoxed(y int, msg string, args ...interface{}) {
+// WriteBoxed writes the (possibly multi line) message centered horizontally starting at line y,
+// inside a round box. Returns the panel occupied by the box (border included), e.g. to position
+// other elements relative to it or to Clear it later.
+func (ap *AnsiPixels) WriteBoxed(y int, msg string, args ...interface{}) *Panel {
 	s := fmt.Sprintf(msg, args...)
 	lines := strings.Split(s, "\n")
 	maxw := 0
@@ -429,10 +1005,14 @@ func (ap *AnsiPixels) WriteBoxed(y int, msg string, args ...interface{}) {
 		ap.MoveCursor(x, y+i)
 		ap.WriteString(l)
 	}
-	ap.DrawRoundBox((ap.W-maxw)/2-1, y-1, maxw+2, len(lines)+2)
+	box := ap.NewPanel((ap.W-maxw)/2-1, y-1, maxw+2, len(lines)+2)
+	box.DrawRoundBox()
+	return box
 }
 
-func (ap *AnsiPixels) WriteRightBoxed(y int, msg string, args ...interface{}) {
+// WriteRightBoxed writes the message in a round box in the right corner at line y.
+// Returns the panel occupied by the box (border included).
+func (ap *AnsiPixels) WriteRightBoxed(y int, msg string, args ...interface{}) *Panel {
 	s := fmt.Sprintf(msg, args...)
 	w := ap.ScreenWidth(s)
 	x := ap.W - w // not using margin as we assume we want to join lines in the corner
@@ -442,7 +1022,9 @@ func (ap *AnsiPixels) WriteRightBoxed(y int, msg string, args ...interface{}) {
 	ap.WriteRune(' ')
 	ap.MoveHorizontally(x)
 	ap.WriteString(s)
-	ap.DrawRoundBox(x-1, y-1, w+2, 3)
+	box := ap.NewPanel(x-1, y-1, w+2, 3)
+	box.DrawRoundBox()
+	return box
 }
 
 func FormatDate(d *time.Time) string {
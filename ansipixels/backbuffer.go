@@ -0,0 +1,187 @@
+package ansipixels
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// sgrState is the current text style (SGR attributes and colors) of a styled screenBuffer.
+type sgrState struct {
+	attrs  [10]bool // 1 (bold) to 9 (strikethrough).
+	fg, bg string   // color parameters, e.g. "31" or "38;5;214", "" for the default.
+}
+
+// Applies the parameters of an SGR (ESC[...m) sequence.
+func (st *sgrState) apply(params []string) {
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+		n, _ := strconv.Atoi(p) // "" is 0 too.
+		switch {
+		case n == 0:
+			*st = sgrState{}
+		case n <= 9:
+			st.attrs[n] = true
+		case n == 22:
+			st.attrs[1], st.attrs[2] = false, false
+		case n >= 23 && n <= 29:
+			st.attrs[n-20] = false
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			st.fg = p
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			st.bg = p
+		case n == 49:
+			st.bg = ""
+		case n == 38, n == 48:
+			k := 3 // 38;5;n
+			if i+1 < len(params) && params[i+1] == "2" {
+				k = 5 // 38;2;r;g;b
+			}
+			k = min(k, len(params)-i)
+			if n == 38 {
+				st.fg = strings.Join(params[i:i+k], ";")
+			} else {
+				st.bg = strings.Join(params[i:i+k], ";")
+			}
+			i += k - 1
+		}
+	}
+}
+
+// String returns the (absolute, starting with a reset) SGR sequence for the state, "" for the
+// default style.
+func (st *sgrState) String() string {
+	if *st == (sgrState{}) {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\033[0")
+	for i, on := range st.attrs {
+		if on {
+			sb.WriteByte(';')
+			sb.WriteString(strconv.Itoa(i))
+		}
+	}
+	for _, c := range []string{st.fg, st.bg} {
+		if c != "" {
+			sb.WriteByte(';')
+			sb.WriteString(c)
+		}
+	}
+	sb.WriteByte('m')
+	return sb.String()
+}
+
+// Returns a screenBuffer also tracking the style of each cell and sending the other sequences
+// (modes, cursor visibility, OSC...) to passthrough.
+func newStyledScreenBuffer(w, h int, passthrough *bufio.Writer) *screenBuffer {
+	sb := newScreenBuffer(w, h)
+	sb.styles = make([][]string, h)
+	for i := range sb.styles {
+		sb.styles[i] = make([]string, w)
+	}
+	sb.passthrough = passthrough
+	return sb
+}
+
+// Writes to out the sequences updating the screen from the front content to sb's (both must be
+// styled and of the same size), only for the changed cells, and then moves the cursor to sb's
+// position.
+func (sb *screenBuffer) diff(out *strings.Builder, front *screenBuffer) {
+	cx, cy := -1, -1
+	style, styled := "", false
+	for y, row := range sb.cells {
+		for x, g := range row {
+			st := sb.styles[y][x]
+			if g == "" || (g == front.cells[y][x] && st == front.styles[y][x]) {
+				continue // unchanged, or right half of a wide glyph (drawn with it).
+			}
+			if x != cx || y != cy {
+				out.WriteString("\033[" + strconv.Itoa(y+1) + ";" + strconv.Itoa(x+1) + "H")
+			}
+			if st != style || !styled {
+				if st == "" {
+					out.WriteString(Reset)
+				} else {
+					out.WriteString(st)
+				}
+				style, styled = st, true
+			}
+			out.WriteString(g)
+			cx, cy = x+max(1, uniseg.StringWidth(g)), y
+		}
+	}
+	if style != "" {
+		out.WriteString(Reset)
+	}
+	if (sb.x != cx || sb.y != cy) && out.Len() > 0 {
+		out.WriteString("\033[" + strconv.Itoa(sb.y+1) + ";" + strconv.Itoa(sb.x+1) + "H")
+	}
+	for y := range sb.cells {
+		copy(front.cells[y], sb.cells[y])
+		copy(front.styles[y], sb.styles[y])
+	}
+	front.x, front.y = sb.x, sb.y
+}
+
+// doubleBuffer holds the frame being drawn and the one on screen, see SetDoubleBuffer.
+type doubleBuffer struct {
+	out   *bufio.Writer // the actual output.
+	back  *screenBuffer
+	front *screenBuffer
+}
+
+// SetDoubleBuffer turns on (or off) double buffering: drawing (WriteAt, WriteRune, MoveCursor...
+// everything written to ap.Out) then goes to an in memory screen and [Present] (also called by
+// [EndSyncMode]) sends only the cells that changed since the previous frame, saving most of the
+// output for apps redrawing the whole screen for each frame with few changes. The other sequences
+// (cursor visibility, mouse mode, clipboard...) are passed through as is; images (Sixel, kitty
+// graphics) aren't supported in that mode, nor is combining it with [SetMaxBytesPerSecond]. The
+// screen is cleared when turning it on and on resize (see [GetSize]).
+func (ap *AnsiPixels) SetDoubleBuffer(enabled bool) {
+	if enabled == (ap.dbuf != nil) || ap.snapshot != nil {
+		return
+	}
+	if !enabled {
+		_ = ap.Present()
+		ap.Out = ap.dbuf.out
+		ap.dbuf = nil
+		return
+	}
+	_ = ap.Out.Flush()
+	ap.dbuf = &doubleBuffer{out: ap.Out}
+	ap.Out = bufio.NewWriterSize(nil, ap.Out.Size())
+	ap.resetDoubleBuffer()
+}
+
+// Starts over, at the current size, from a cleared screen.
+func (ap *AnsiPixels) resetDoubleBuffer() {
+	db := ap.dbuf
+	_ = ap.Out.Flush() // drop what's drawn at the old size (OnResize redraws).
+	db.back = newStyledScreenBuffer(ap.W, ap.H, db.out)
+	db.front = newStyledScreenBuffer(ap.W, ap.H, nil)
+	ap.Out.Reset(db.back)
+	_, _ = db.out.WriteString("\033[2J")
+}
+
+// Present sends the changes of the frame drawn since the last call to the terminal when double
+// buffering is on (see [SetDoubleBuffer]), or just flushes the output otherwise.
+func (ap *AnsiPixels) Present() error {
+	db := ap.dbuf
+	if db == nil {
+		return ap.Out.Flush()
+	}
+	if err := ap.Out.Flush(); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	db.back.diff(&sb, db.front)
+	if sb.Len() > 0 {
+		_, _ = db.out.WriteString("\033[?2026h" + sb.String() + "\033[?2026l")
+	}
+	return db.out.Flush()
+}
@@ -0,0 +1,60 @@
+package ansipixels
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestDoubleBuffer(t *testing.T) {
+	var out strings.Builder
+	ap := &AnsiPixels{W: 40, H: 12, Out: bufio.NewWriter(&out)}
+	ap.SetDoubleBuffer(true)
+	frame := func(counter string) {
+		ap.ClearScreen()
+		ap.WriteAtStr(0, 0, Red+"Title"+Reset)
+		ap.WriteAtStr(2, 1, "counter: "+counter)
+		ap.WriteAtStr(0, 3, Blue+"日本"+Reset+" footer")
+		for y := 4; y < ap.H; y++ {
+			ap.WriteAtStr(0, y, Green+strings.Repeat("-", ap.W)+Reset)
+		}
+		ap.MoveCursor(0, 2)
+		ap.HideCursor()
+	}
+	frame("1")
+	if err := ap.Present(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := out.String()
+	out.Reset()
+	frame("2")
+	ap.EndSyncMode() // same as Present when double buffering.
+	second := out.String()
+	const maxDiff = 40 // sync mode, hide cursor, 1 move, the cell, 1 move back.
+	if len(second) > maxDiff || !strings.Contains(second, "2") {
+		t.Errorf("diff output too big (%d bytes, %d for the first frame): %q", len(second), len(first), second)
+	}
+	if len(second) >= len(first)/10 {
+		t.Errorf("expected the diff (%d) to be much smaller than the first frame (%d)", len(second), len(first))
+	}
+	screen := newScreenBuffer(40, 12)
+	_, _ = screen.Write([]byte(first + second))
+	expected := "Title\n  counter: 2\n\n日本 footer\n" + strings.Repeat(strings.Repeat("-", 40)+"\n", 8)
+	if got := screen.String(); got != expected {
+		t.Errorf("unexpected screen %q, expected %q", got, expected)
+	}
+	if screen.x != 0 || screen.y != 2 {
+		t.Errorf("cursor at %d,%d, expected 0,2", screen.x, screen.y)
+	}
+	out.Reset()
+	ap.EndSyncMode() // nothing changed.
+	if got := out.String(); got != "" {
+		t.Errorf("expected no output for an identical frame, got %q", got)
+	}
+	ap.SetDoubleBuffer(false)
+	ap.WriteAtStr(0, 0, "x")
+	_ = ap.Out.Flush()
+	if !strings.HasSuffix(out.String(), "x") {
+		t.Errorf("expected direct output after turning double buffering off, got %q", out.String())
+	}
+}
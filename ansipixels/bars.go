@@ -0,0 +1,61 @@
+package ansipixels
+
+import (
+	"math"
+	"strings"
+)
+
+// Left eighth blocks, from 1/8 to 8/8 (full) of a cell's width.
+var leftEighths = []rune("▏▎▍▌▋▊▉█")
+
+// eighths returns the number of eighths of cells for fraction (clamped to [0,1]) of size cells.
+func eighths(fraction float64, size int) int {
+	fraction = min(max(fraction, 0), 1)
+	return int(math.Round(fraction * float64(size) * 8))
+}
+
+// HBarRunes returns a horizontal bar filling fraction (0 to 1) of width cells, with sub cell
+// precision using the left eighth block runes, padded with spaces to width. E.g. 0.52 of 20
+// cells is 10 full blocks and a 3/8 block.
+func (ap *AnsiPixels) HBarRunes(fraction float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := eighths(fraction, width)
+	full, rest := n/8, n%8
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat(string(FullPixel), full))
+	if rest > 0 {
+		sb.WriteRune(leftEighths[rest-1])
+		full++
+	}
+	sb.WriteString(strings.Repeat(" ", width-full))
+	return sb.String()
+}
+
+// Lower eighth blocks, from 1/8 to 8/8 (full) of a cell's height.
+var lowerEighths = []rune("▁▂▃▄▅▆▇█")
+
+// VBarRunes returns a vertical bar (for column charts) filling fraction (0 to 1) of height cells,
+// with sub cell precision using the lower eighth block runes. The result is one single character
+// string per cell from top to bottom, with spaces above the bar.
+func (ap *AnsiPixels) VBarRunes(fraction float64, height int) []string {
+	if height <= 0 {
+		return nil
+	}
+	n := eighths(fraction, height)
+	full, rest := n/8, n%8
+	res := make([]string, height)
+	for i := range res {
+		fromBottom := height - 1 - i
+		switch {
+		case fromBottom < full:
+			res[i] = string(FullPixel)
+		case fromBottom == full && rest > 0:
+			res[i] = string(lowerEighths[rest-1])
+		default:
+			res[i] = " "
+		}
+	}
+	return res
+}
@@ -0,0 +1,38 @@
+package ansipixels
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHBarRunes(t *testing.T) {
+	ap := &AnsiPixels{}
+	tests := []struct {
+		fraction float64
+		width    int
+		expected string
+	}{
+		{0, 3, "   "},
+		{1, 3, "███"},
+		{0.52, 5, "██▋  "},
+		{1.5, 2, "██"},
+		{0.5, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := ap.HBarRunes(tt.fraction, tt.width); got != tt.expected {
+			t.Errorf("HBarRunes(%v, %d) = %q, expected %q", tt.fraction, tt.width, got, tt.expected)
+		}
+	}
+}
+
+func TestVBarRunes(t *testing.T) {
+	ap := &AnsiPixels{}
+	got := strings.Join(ap.VBarRunes(0.45, 4), "")
+	if expected := "  ▆█"; got != expected {
+		t.Errorf("VBarRunes(0.45, 4) = %q, expected %q", got, expected)
+	}
+	got = strings.Join(ap.VBarRunes(1, 2), "")
+	if expected := "██"; got != expected {
+		t.Errorf("VBarRunes(1, 2) = %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,148 @@
+package ansipixels
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fortio.org/log"
+)
+
+// Capabilities are the terminal features found by [QueryCapabilities].
+type Capabilities struct {
+	// Terminal name and version from XTVERSION, e.g. "kitty(0.35.2)" or "XTerm(390)", "" when
+	// not answered.
+	Name string
+	// Primary device attributes (DA1) parameters, e.g. 4 for Sixel graphics.
+	Attributes []int
+	// 24 bit colors (38;2;r;g;b sequences) are supported.
+	TrueColor bool
+	// Sixel graphics images are supported.
+	Sixel bool
+	// Kitty graphics protocol images are supported.
+	KittyGraphics bool
+	// Mouse coordinates in pixels (see [MousePixelsOn]) are supported.
+	MousePixels bool
+	// Size in pixels of a character cell, 0 when not answered (see [ReadCellSize]).
+	CellWidth, CellHeight int
+	// False when the terminal didn't answer and the capabilities are guessed from the environment
+	// (TERM and COLORTERM) instead.
+	Queried bool
+}
+
+// How long QueryCapabilities and ReadCellSize wait for the terminal's answers.
+const queryTimeout = 500 * time.Millisecond
+
+// The primary device attributes response, which all terminals send and is requested last: the
+// answers to the other queries, if any, are before it.
+var da1Regexp = regexp.MustCompile(`^(.*)\033\[\?([\d;]*)c(.*)$`)
+
+// Responses to the other queries of QueryCapabilities.
+var (
+	xtVersionRegexp = regexp.MustCompile(`\033P>\|([^\033]*)\033\\`)
+	decrqssSGRegexp = regexp.MustCompile(`\033P[01]\$r([^\033]*)\033\\`)
+	mousePixRegexp  = regexp.MustCompile(`\033\[\?1016;(\d)\$y`)
+	kittyRespRegexp = regexp.MustCompile(`\033_Gi=31;([^\033]*)\033\\`)
+	trueColorProbe  = regexp.MustCompile(`38[:;]2[:;]+1[:;]2[:;]3(\D|$)`)
+)
+
+// The queries: XTVERSION, a true color SGR set and read back (DECRQSS) then reset, the SGR-Pixels
+// mouse mode state (DECRQM), a kitty graphics 1 pixel query, the cell size in pixels and finally DA1.
+const capabilitiesRequest = "\033[>q" +
+	"\033[38;2;1;2;3m\033P$qm\033\\\033[m" +
+	"\033[?1016$p" +
+	"\033_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\033\\" +
+	"\033[16t" +
+	"\033[c"
+
+// QueryCapabilities asks the terminal what it supports (using primary device attributes, XTVERSION,
+// a true color probe and more) and sets ap's [TrueColor], [Sixel] and [KittyGraphics] accordingly,
+// and the cell size (see [ReadCellSize]) when answered.
+// When the terminal doesn't answer in time (or in snapshot mode), the capabilities are guessed from
+// the environment instead (see [DetectTrueColor], [DetectSixel] and [DetectKittyGraphics]) and the
+// error is returned along with them. Like ReadCursorPos, this also synchronizes the display and
+// ends the syncmode.
+func (ap *AnsiPixels) QueryCapabilities() (Capabilities, error) {
+	var err error
+	if ap.snapshot != nil {
+		err = errSnapshotMode
+	} else {
+		var res [][]byte
+		res, err = ap.queryWithTimeout(capabilitiesRequest, da1Regexp, "device attributes")
+		if err == nil && res == nil {
+			err = errors.New("no device attributes response")
+		}
+		if err == nil {
+			caps := ap.parseCapabilities(string(res[2]))
+			ap.TrueColor, ap.Sixel, ap.KittyGraphics = caps.TrueColor, caps.Sixel, caps.KittyGraphics
+			return caps, nil
+		}
+	}
+	log.LogVf("No capabilities response, guessing from the environment: %v", err)
+	caps := Capabilities{TrueColor: DetectTrueColor(), Sixel: DetectSixel(), KittyGraphics: DetectKittyGraphics()}
+	ap.TrueColor, ap.Sixel, ap.KittyGraphics = caps.TrueColor, caps.Sixel, caps.KittyGraphics
+	return caps, err
+}
+
+// Like query, from InWithTimeout with the [queryTimeout] instead of the frame one, so terminals
+// not answering don't block forever.
+func (ap *AnsiPixels) queryWithTimeout(request string, re *regexp.Regexp, what string) ([][]byte, error) {
+	ap.InWithTimeout.ChangeTimeout(queryTimeout)
+	res, err := ap.query(ap.InWithTimeout, request, re, what)
+	fps := ap.FPS
+	if ap.idle {
+		fps = ap.IdleFPS
+	}
+	ap.ChangeFPS(fps)
+	return res, err
+}
+
+// Parses the DA1 parameters and the other responses found in ap.Data, which are removed from it.
+func (ap *AnsiPixels) parseCapabilities(da1 string) Capabilities {
+	caps := Capabilities{Queried: true}
+	for _, p := range strings.Split(da1, ";") {
+		if v, err := strconv.Atoi(p); err == nil {
+			caps.Attributes = append(caps.Attributes, v)
+			caps.Sixel = caps.Sixel || v == 4
+		}
+	}
+	if m := ap.extractResponse(xtVersionRegexp); m != nil {
+		caps.Name = string(m)
+	}
+	if m := ap.extractResponse(decrqssSGRegexp); m != nil {
+		caps.TrueColor = trueColorProbe.Match(m)
+	}
+	if m := ap.extractResponse(mousePixRegexp); m != nil {
+		caps.MousePixels = m[0] >= '1' && m[0] <= '3' // set, reset or permanently set.
+	}
+	if m := ap.extractResponse(kittyRespRegexp); m != nil {
+		caps.KittyGraphics = bytes.Equal(m, []byte("OK"))
+	}
+	if ap.cellErr = ap.parseCellSize(); ap.cellErr == nil {
+		caps.CellWidth, caps.CellHeight = ap.cellW, ap.cellH
+	}
+	return caps
+}
+
+// Removes the first match of re (with 1 group) from ap.Data and returns its group, nil if not found.
+func (ap *AnsiPixels) extractResponse(re *regexp.Regexp) []byte {
+	loc := re.FindSubmatchIndex(ap.Data)
+	if loc == nil {
+		return nil
+	}
+	res := bytes.Clone(ap.Data[loc[2]:loc[3]])
+	ap.Data = append(ap.Data[:loc[0]], ap.Data[loc[1]:]...)
+	return res
+}
+
+// DetectTrueColor returns whether the terminal supports 24 bit colors according to COLORTERM
+// ("truecolor" or "24bit"), e.g. to set [TrueColor] by default. See [QueryCapabilities] for a more
+// reliable way.
+func DetectTrueColor() bool {
+	ct := strings.ToLower(os.Getenv("COLORTERM"))
+	return ct == "truecolor" || ct == "24bit"
+}
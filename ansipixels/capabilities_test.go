@@ -0,0 +1,75 @@
+package ansipixels
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"fortio.org/terminal"
+)
+
+func TestQueryCapabilities(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	var out strings.Builder
+	ap := &AnsiPixels{
+		Out:           bufio.NewWriter(&out),
+		InWithTimeout: terminal.NewTimeoutReader(r, time.Second),
+		FPS:           60,
+	}
+	// Typical answers, in order, with a key pressed in the middle.
+	_, _ = w.WriteString("\033P>|kitty(0.35.2)\033\\" + "\033P1$r0;38:2:1:2:3m\033\\" + "x" +
+		"\033[?1016;2$y" + "\033_Gi=31;OK\033\\" + "\033[6;20;10t" + "\033[?62;4;22c")
+	caps, err := ap.QueryCapabilities()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "\033[?2026l"+capabilitiesRequest {
+		t.Errorf("unexpected request %q", out.String())
+	}
+	if caps.Name != "kitty(0.35.2)" || !caps.TrueColor || !caps.Sixel || !caps.KittyGraphics ||
+		!caps.MousePixels || !caps.Queried || len(caps.Attributes) != 3 || caps.CellWidth != 10 || caps.CellHeight != 20 {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+	if !ap.TrueColor || !ap.Sixel || !ap.KittyGraphics {
+		t.Errorf("ap fields not set from the capabilities")
+	}
+	if string(ap.Data) != "x" {
+		t.Errorf("expected the key to be left in Data, got %q", ap.Data)
+	}
+	// A terminal only answering DA1, with 256 colors.
+	_, _ = w.WriteString("\033P1$r0;38;5;16m\033\\\033[?1;2c")
+	caps, err = ap.QueryCapabilities()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.Name != "" || caps.TrueColor || caps.Sixel || caps.KittyGraphics || caps.MousePixels || !caps.Queried {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+	if _, _, err = ap.CellPixelSize(); err == nil {
+		t.Errorf("expected the cell size to be unknown without answer")
+	}
+}
+
+func TestQueryCapabilitiesFallback(t *testing.T) {
+	t.Setenv("COLORTERM", "truecolor")
+	t.Setenv("TERM", "foot")
+	sb := newScreenBuffer(10, 2)
+	ap := &AnsiPixels{W: 10, H: 2, Out: bufio.NewWriter(sb), snapshot: sb}
+	caps, err := ap.QueryCapabilities()
+	if err == nil {
+		t.Errorf("expected an error in snapshot mode")
+	}
+	if !caps.TrueColor || !caps.Sixel || caps.KittyGraphics || caps.Queried {
+		t.Errorf("unexpected capabilities from the environment %+v", caps)
+	}
+	if !ap.TrueColor || !ap.Sixel {
+		t.Errorf("ap fields not set from the environment")
+	}
+}
@@ -2,11 +2,13 @@ package ansipixels
 
 // Ansi codes.
 const (
-	Bold       = "\x1b[1m"
-	Dim        = "\x1b[2m"
-	Underlined = "\x1b[4m"
-	Blink      = "\x1b[5m"
-	Reverse    = "\x1b[7m"
+	Bold          = "\x1b[1m"
+	Dim           = "\x1b[2m"
+	Italic        = "\x1b[3m"
+	Underlined    = "\x1b[4m"
+	Blink         = "\x1b[5m"
+	Reverse       = "\x1b[7m"
+	Strikethrough = "\x1b[9m"
 
 	MoveLeft = "\033[1D"
 
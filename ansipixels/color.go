@@ -0,0 +1,264 @@
+package ansipixels
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HSLToRGB converts HSL values to RGB. h, s and l in [0,1].
+// Initially from grol.io/grol/extensions images.go.
+func HSLToRGB(h, s, l float64) color.NRGBA {
+	var r, g, b float64
+
+	if s == 0 {
+		r, g, b = l, l, l
+	} else {
+		var q float64
+		if l < 0.5 {
+			q = l * (1. + s)
+		} else {
+			q = l + s - l*s
+		}
+		p := 2*l - q
+		r = hueToRGB(p, q, h+1/3.)
+		g = hueToRGB(p, q, h)
+		b = hueToRGB(p, q, h-1/3.)
+	}
+
+	return color.NRGBA{
+		R: uint8(math.Round(r * 255)),
+		G: uint8(math.Round(g * 255)),
+		B: uint8(math.Round(b * 255)),
+		A: 255,
+	}
+}
+
+func hueToRGB(p, q, t float64) float64 {
+	if t < 0 {
+		t += 1.
+	}
+	if t > 1 {
+		t -= 1.
+	}
+	if t < 1/6. {
+		return p + (q-p)*6*t
+	}
+	if t < 0.5 {
+		return q
+	}
+	if t < 2/3. {
+		return p + (q-p)*(2/3.-t)*6
+	}
+	return p
+}
+
+// HSVColor is a color in the HSV (also known as HSB) model, H, S and V in [0,1].
+type HSVColor struct {
+	H, S, V float64
+}
+
+// RGB converts the color to RGB, see [HSVToRGB].
+func (c HSVColor) RGB() color.NRGBA {
+	return HSVToRGB(c.H, c.S, c.V)
+}
+
+// ToHSV converts an RGB color to HSV, see [RGBToHSV].
+func ToHSV(c color.NRGBA) HSVColor {
+	h, s, v := RGBToHSV(c)
+	return HSVColor{H: h, S: s, V: v}
+}
+
+// HSVToRGB converts HSV (also known as HSB) values to RGB. h, s and v in [0,1].
+func HSVToRGB(h, s, v float64) color.NRGBA {
+	h = 6 * (h - math.Floor(h))
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g, b = c, x, 0
+	case h < 2:
+		r, g, b = x, c, 0
+	case h < 3:
+		r, g, b = 0, c, x
+	case h < 4:
+		r, g, b = 0, x, c
+	case h < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := v - c
+	return color.NRGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 255,
+	}
+}
+
+// RGBToHSV converts a color to HSV (HSB) values, h, s and v in [0,1] (h is 0 for grays).
+func RGBToHSV(c color.NRGBA) (h, s, v float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC, minC := max(r, g, b), min(r, g, b)
+	v = maxC
+	d := maxC - minC
+	if maxC > 0 {
+		s = d / maxC
+	}
+	if d == 0 {
+		return 0, s, v
+	}
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h / 6, s, v
+}
+
+// ParseHSV parses the hsv(h s v) string form, like the css hsl() one: h in degrees (with an
+// optional deg unit), s and v in percent (e.g. "hsv(210 50% 80%)"); commas are also accepted
+// as separators.
+func ParseHSV(str string) (color.NRGBA, error) {
+	parts, err := colorFunction(str, "hsv")
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+	if len(parts) != 3 {
+		return color.NRGBA{}, fmt.Errorf("invalid hsv color %q, expected 3 components", str)
+	}
+	var vals [3]float64
+	for i, p := range parts {
+		unit := "%"
+		if i == 0 {
+			unit = "deg"
+		}
+		f, err := strconv.ParseFloat(strings.TrimSuffix(p, unit), 64)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("invalid hsv component %q in %q: %w", p, str, err)
+		}
+		vals[i] = f
+	}
+	if vals[1] < 0 || vals[1] > 100 || vals[2] < 0 || vals[2] > 100 {
+		return color.NRGBA{}, fmt.Errorf("invalid hsv color %q, s and v must be between 0%% and 100%%", str)
+	}
+	return HSVToRGB(vals[0]/360, vals[1]/100, vals[2]/100), nil
+}
+
+// ParseRGB parses the css rgb(r g b) and rgba(r g b / a) forms (as copied from browsers devtools),
+// with r, g and b between 0 and 255 and the optional alpha between 0 and 1 (or 0% and 100%).
+// Commas are also accepted as separators, e.g. "rgba(255, 87, 51, 0.5)". Like for
+// [ParseHexColor], the returned color is not premultiplied by the alpha.
+func ParseRGB(str string) (color.RGBA, error) {
+	parts, err := colorFunction(str, "rgba")
+	if err != nil {
+		parts, err = colorFunction(str, "rgb")
+	}
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	if len(parts) == 5 && parts[3] == "/" {
+		parts = append(parts[:3], parts[4])
+	}
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.RGBA{}, fmt.Errorf("invalid rgb color %q, expected 3 components and an optional alpha", str)
+	}
+	var vals [4]uint8
+	vals[3] = 255
+	for i, p := range parts {
+		maxV := 255.
+		if i == 3 {
+			maxV = 1
+			if pct, ok := strings.CutSuffix(p, "%"); ok {
+				p, maxV = pct, 100
+			}
+		}
+		f, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid rgb component %q in %q: %w", p, str, err)
+		}
+		if f < 0 || f > maxV {
+			return color.RGBA{}, fmt.Errorf("invalid rgb component %q in %q, must be between 0 and %g", p, str, maxV)
+		}
+		vals[i] = uint8(math.Round(f / maxV * 255))
+	}
+	return color.RGBA{R: vals[0], G: vals[1], B: vals[2], A: vals[3]}, nil
+}
+
+// Returns the components of a name(a b c) (or name(a, b, c)) color string.
+func colorFunction(str, name string) ([]string, error) {
+	inner, hasPrefix := strings.CutPrefix(strings.ToLower(strings.TrimSpace(str)), name+"(")
+	inner, hasSuffix := strings.CutSuffix(inner, ")")
+	if !hasPrefix || !hasSuffix {
+		return nil, fmt.Errorf("invalid %s color %q, expected %s(...)", name, str, name)
+	}
+	return strings.Fields(strings.NewReplacer(",", " ", "/", " / ").Replace(inner)), nil
+}
+
+// ParseHexColor parses a hex color, with an optional # prefix: RGB (each digit is repeated, so
+// f00 is ff0000), RRGGBB or RRGGBBAA (with alpha, which is otherwise 255). Note that the returned
+// color is not premultiplied by the alpha (i.e. it's a color.NRGBA, in a color.RGBA).
+func ParseHexColor(str string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(str), "#")
+	switch len(hex) {
+	case 3:
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]}) + "ff"
+	case 6:
+		hex += "ff"
+	case 8:
+	default:
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q, expected 3, 6 or 8 hex digits", str)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %q: %w", str, err)
+	}
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil //nolint:gosec // byte extraction.
+}
+
+// ColorFlag is a [flag.Value] accepting any of the [ParseColor] forms, e.g.
+//
+//	bg := ansipixels.ColorFlag{A: 255} // default: black.
+//	flag.Var(&bg, "bg", "Background `color`, e.g. #333 or rgb(40 40 40)")
+//
+// and then use color.RGBA(bg).
+type ColorFlag color.RGBA
+
+// Set parses s, see [ParseColor].
+func (c *ColorFlag) Set(s string) error {
+	v, err := ParseColor(s)
+	if err != nil {
+		return err
+	}
+	*c = ColorFlag(v)
+	return nil
+}
+
+// String returns the color in the #RRGGBB form, #RRGGBBAA when not opaque.
+func (c *ColorFlag) String() string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// ParseColor parses either the hsv(h s v) form (see [ParseHSV]), the rgb(r g b) and rgba(r g b / a)
+// forms (see [ParseRGB]) or a hex color (see [ParseHexColor]).
+func ParseColor(str string) (color.RGBA, error) {
+	lower := strings.ToLower(strings.TrimSpace(str))
+	switch {
+	case strings.HasPrefix(lower, "hsv"):
+		c, err := ParseHSV(str)
+		return color.RGBA(c), err
+	case strings.HasPrefix(lower, "rgb"):
+		return ParseRGB(str)
+	}
+	return ParseHexColor(str)
+}
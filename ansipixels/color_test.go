@@ -0,0 +1,100 @@
+package ansipixels
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestHSVRGBExactRoundTrip(t *testing.T) {
+	for r := 0; r < 256; r += 5 {
+		for g := 0; g < 256; g += 3 {
+			for b := 0; b < 256; b += 7 {
+				c := color.NRGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
+				h, s, v := RGBToHSV(c)
+				if h < 0 || h >= 1 || s < 0 || s > 1 || v < 0 || v > 1 {
+					t.Fatalf("out of range hsv %v %v %v for %v", h, s, v, c)
+				}
+				if got := HSVToRGB(h, s, v); got != c {
+					t.Fatalf("round trip of %v through hsv %v %v %v got %v", c, h, s, v, got)
+				}
+				if got := ToHSV(c).RGB(); got != c {
+					t.Fatalf("round trip of %v through HSVColor %+v got %v", c, ToHSV(c), got)
+				}
+			}
+		}
+	}
+}
+
+func TestParseHSV(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected color.NRGBA
+	}{
+		{"hsv(0 100% 100%)", color.NRGBA{255, 0, 0, 255}},
+		{"hsv(120deg 100% 50%)", color.NRGBA{0, 128, 0, 255}},
+		{"HSV(240, 100%, 100%)", color.NRGBA{0, 0, 255, 255}},
+		{"hsv(360 0% 100%)", color.NRGBA{255, 255, 255, 255}},
+		{"hsv(210 50% 80%)", color.NRGBA{102, 153, 204, 255}},
+	}
+	for _, tc := range tests {
+		got, err := ParseHSV(tc.input)
+		if err != nil || got != tc.expected {
+			t.Errorf("ParseHSV(%q) = %v, %v; expected %v", tc.input, got, err, tc.expected)
+		}
+	}
+	for _, bad := range []string{"hsv(1 2)", "hsl(0 100% 50%)", "hsv(0 101% 50%)", "hsv(x 1% 1%)", "(0 1% 1%)"} {
+		if _, err := ParseHSV(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected color.RGBA
+	}{
+		{"fff", color.RGBA{255, 255, 255, 255}},
+		{"#0a0", color.RGBA{0, 0xaa, 0, 255}},
+		{"#f00", color.RGBA{255, 0, 0, 255}},
+		{"11223344", color.RGBA{0x11, 0x22, 0x33, 0x44}},
+		{"#A0B1C2", color.RGBA{0xa0, 0xb1, 0xc2, 255}},
+		{"hsv(0 100% 100%)", color.RGBA{255, 0, 0, 255}},
+		{"rgb(255 87 51)", color.RGBA{255, 87, 51, 255}},
+		{"rgb(255, 87, 51)", color.RGBA{255, 87, 51, 255}},
+		{"rgba(255 87 51 / 0.5)", color.RGBA{255, 87, 51, 128}},
+		{"RGBA(0, 0, 0, 0)", color.RGBA{0, 0, 0, 0}},
+		{"rgba(1 2 3/50%)", color.RGBA{1, 2, 3, 128}},
+	}
+	for _, tc := range tests {
+		got, err := ParseColor(tc.input)
+		if err != nil || got != tc.expected {
+			t.Errorf("ParseColor(%q) = %v, %v; expected %v", tc.input, got, err, tc.expected)
+		}
+	}
+	for _, bad := range []string{"", "#ff", "12345", "#ggg", "+1234567", "hsv(1)",
+		"rgb(256 0 0)", "rgb(-1 0 0)", "rgba(0 0 0 / 1.5)", "rgba(0 0 0 / 101%)", "rgb(1 2)", "rgb(1 2 3 4 5)", "rgb(a b c)"} {
+		if _, err := ParseColor(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestColorFlag(t *testing.T) {
+	c := ColorFlag{A: 255}
+	if c.String() != "#000000" {
+		t.Errorf("unexpected default %q", c.String())
+	}
+	if err := c.Set("rgba(255 87 51 / 0.5)"); err != nil || color.RGBA(c) != (color.RGBA{255, 87, 51, 128}) {
+		t.Errorf("unexpected Set result %v, %v", c, err)
+	}
+	if c.String() != "#ff573380" {
+		t.Errorf("unexpected String %q", c.String())
+	}
+	if err := c.Set("#12"); err == nil {
+		t.Errorf("expected error for invalid color")
+	}
+	if err := c.Set("#0a0"); err != nil || c.String() != "#00aa00" {
+		t.Errorf("unexpected %q, %v", c.String(), err)
+	}
+}
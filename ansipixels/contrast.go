@@ -0,0 +1,40 @@
+package ansipixels
+
+import (
+	"image/color"
+	"math"
+)
+
+// SrgbToLinear converts an sRGB component (0-255) to linear light (in [0,1]).
+func SrgbToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// RelativeLuminance returns the WCAG relative luminance of c, in [0,1] (alpha is ignored).
+func RelativeLuminance(c color.RGBA) float64 {
+	return 0.2126*SrgbToLinear(c.R) + 0.7152*SrgbToLinear(c.G) + 0.0722*SrgbToLinear(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between a and b, from 1 (same luminance) to 21
+// (black and white). WCAG AA asks for at least 4.5 for normal text.
+func ContrastRatio(a, b color.RGBA) float64 {
+	la, lb := RelativeLuminance(a), RelativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// ReadableForeground returns black or white, whichever has the highest contrast with bg, e.g. for
+// the text of colored table cells or boxes.
+func ReadableForeground(bg color.RGBA) color.RGBA {
+	black, white := color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	if ContrastRatio(bg, black) >= ContrastRatio(bg, white) {
+		return black
+	}
+	return white
+}
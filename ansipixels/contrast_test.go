@@ -0,0 +1,39 @@
+package ansipixels
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestContrastRatio(t *testing.T) {
+	black := color.RGBA{A: 255}
+	white := color.RGBA{255, 255, 255, 255}
+	tests := []struct {
+		a, b     color.RGBA
+		expected float64
+	}{
+		{black, white, 21},
+		{white, white, 1},
+		{color.RGBA{0x77, 0x77, 0x77, 255}, white, 4.48},
+		{white, color.RGBA{0x77, 0x77, 0x77, 255}, 4.48},
+		{color.RGBA{0, 0, 255, 255}, white, 8.59},
+		{color.RGBA{255, 0, 0, 255}, black, 5.25},
+	}
+	for _, tc := range tests {
+		if got := ContrastRatio(tc.a, tc.b); math.Abs(got-tc.expected) > 0.005 {
+			t.Errorf("ContrastRatio(%v, %v) = %.3f, expected %.2f", tc.a, tc.b, got, tc.expected)
+		}
+	}
+	for bg, expected := range map[color.RGBA]color.RGBA{
+		black:                   white,
+		white:                   black,
+		{0, 0, 128, 255}:        white,
+		{255, 255, 0, 255}:      black,
+		{0x77, 0x77, 0x77, 255}: black, // 4.48 on white vs 4.69 on black.
+	} {
+		if got := ReadableForeground(bg); got != expected {
+			t.Errorf("ReadableForeground(%v) = %v, expected %v", bg, got, expected)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package ansipixels
+
+import "strconv"
+
+// CursorStyle is the shape of the cursor and whether it blinks, see [SetCursorStyle]. The values
+// are the DECSCUSR parameters.
+type CursorStyle uint8
+
+const (
+	DefaultCursor     CursorStyle = iota // Terminal's default (user configured) cursor.
+	BlinkingBlock                        // █ blinking.
+	SteadyBlock                          // █ not blinking.
+	BlinkingUnderline                    // _ blinking.
+	SteadyUnderline                      // _ not blinking.
+	BlinkingBar                          // │ blinking (e.g. for an insert mode).
+	SteadyBar                            // │ not blinking.
+)
+
+// SetCursorStyle changes the cursor shape, e.g. bar for an insert mode vs block for a command
+// mode. Restore switches back to [DefaultCursor] if another style was set.
+func (ap *AnsiPixels) SetCursorStyle(style CursorStyle) {
+	ap.WriteString("\033[" + strconv.Itoa(int(style)) + " q")
+	ap.cursorStyle = style
+}
@@ -0,0 +1,31 @@
+package ansipixels
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestSetCursorStyle(t *testing.T) {
+	var out strings.Builder
+	ap := &AnsiPixels{Out: bufio.NewWriter(&out)}
+	for style, expected := range map[CursorStyle]string{
+		DefaultCursor:     "\033[0 q",
+		BlinkingBlock:     "\033[1 q",
+		SteadyBlock:       "\033[2 q",
+		BlinkingUnderline: "\033[3 q",
+		SteadyUnderline:   "\033[4 q",
+		BlinkingBar:       "\033[5 q",
+		SteadyBar:         "\033[6 q",
+	} {
+		out.Reset()
+		ap.SetCursorStyle(style)
+		_ = ap.Out.Flush()
+		if got := out.String(); got != expected {
+			t.Errorf("style %d: got %q, expected %q", style, got, expected)
+		}
+		if ap.cursorStyle != style {
+			t.Errorf("style %d not recorded, got %d", style, ap.cursorStyle)
+		}
+	}
+}
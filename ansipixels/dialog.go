@@ -0,0 +1,54 @@
+package ansipixels
+
+import (
+	"strings"
+
+	"fortio.org/log"
+)
+
+// Confirm shows message (can be multiple lines) and " (y/n)" in a centered box and waits
+// for the user to answer. Mouse reporting is turned off while waiting. Returns true for y/Y and
+// false for n/N, q/Q, Escape, ^C or if an error (e.g. a signal) occurred.
+// The screen under the box is then restored by redrawing it with OnResize (also called if the
+// terminal is resized while waiting). When OnResize isn't set, the caller should redraw it.
+func (ap *AnsiPixels) Confirm(message string) bool {
+	ap.PushMouseMode(NoMouse)
+	defer ap.PopMouseMode()
+	answer := ap.confirm(message)
+	if ap.OnResize != nil {
+		ap.ClearScreen()
+		if err := ap.OnResize(); err != nil {
+			log.Errf("Error redrawing after Confirm: %v", err)
+		}
+		ap.EndSyncMode()
+	}
+	return answer
+}
+
+func (ap *AnsiPixels) confirm(message string) bool {
+	message += " (y/n)"
+	lines := strings.Split(message, "\n")
+	for {
+		maxw := 0
+		for _, l := range lines {
+			maxw = max(maxw, ap.ScreenWidth(l))
+		}
+		y := (ap.H - len(lines)) / 2
+		ap.NewPanel((ap.W-maxw)/2-1, y-1, maxw+2, len(lines)+2).Clear()
+		ap.WriteBoxed(y, "%s", message)
+		err := ap.ReadOrResizeOrSignal() // flushes.
+		if err != nil {
+			log.Infof("Confirm interrupted: %v", err)
+			return false
+		}
+		if len(ap.Data) == 0 { // resize or mouse event.
+			continue
+		}
+		switch ap.Data[0] {
+		case 'y', 'Y':
+			return true
+		case 'n', 'N', 'q', 'Q', 27, 3:
+			return false
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ansipixels
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+
+	"fortio.org/terminal"
+)
+
+func TestConfirmRestoresScreen(t *testing.T) {
+	sb := newScreenBuffer(20, 5)
+	ap := &AnsiPixels{W: 20, H: 5, Out: bufio.NewWriter(sb), snapshot: sb, C: make(chan os.Signal)}
+	ap.OnResize = func() error {
+		ap.WriteAtStr(0, 2, "under the box")
+		return nil
+	}
+	_ = ap.OnResize()
+	for _, tc := range []struct {
+		key      string
+		expected bool
+	}{
+		{"n", false},
+		{"x", false}, // ignored, then n.
+		{"Y", true},
+	} {
+		ap.SetInput(terminal.NewScriptedReader(0, []byte(tc.key), []byte("n")))
+		if got := ap.Confirm("Quit?"); got != tc.expected {
+			t.Errorf("Confirm with %q = %t, expected %t", tc.key, got, tc.expected)
+		}
+		if got := ap.Snapshot(); got != "\n\nunder the box\n\n\n" {
+			t.Errorf("screen not restored after %q: %q", tc.key, got)
+		}
+	}
+	// Without OnResize the box stays, for the caller to redraw.
+	ap.OnResize = nil
+	ap.SetInput(terminal.NewScriptedReader(0, []byte("n")))
+	ap.Confirm("Quit?")
+	if got := ap.Snapshot(); !strings.Contains(got, "Quit? (y/n)") {
+		t.Errorf("expected the box still on screen, got %q", got)
+	}
+}
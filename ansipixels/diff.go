@@ -0,0 +1,38 @@
+package ansipixels
+
+import "strings"
+
+// DiffKind is the type of a line in a diff, see [WriteDiffLine].
+type DiffKind int
+
+const (
+	DiffContext DiffKind = iota // unchanged line, shown dimmed with a " " prefix.
+	DiffAdded                   // added line, green with a "+" prefix.
+	DiffRemoved                 // removed line, red with a "-" prefix.
+)
+
+var diffStyles = [...]struct {
+	prefix string
+	style  string
+}{
+	DiffContext: {" ", Dim},
+	DiffAdded:   {"+", "\033[48;5;22m" + BrightGreen},
+	DiffRemoved: {"-", "\033[48;5;52m" + BrightRed},
+}
+
+// WriteDiffLine writes text as a diff line of the given kind on line y: prefixed and colored,
+// with the background filling the whole width (within the margins). Text too long is truncated
+// and tabs are expanded to 4 spaces.
+func (ap *AnsiPixels) WriteDiffLine(y int, kind DiffKind, text string) {
+	if kind < DiffContext || kind > DiffRemoved {
+		kind = DiffContext
+	}
+	width := ap.W - 2*ap.Margin
+	if width <= 0 {
+		return
+	}
+	ds := diffStyles[kind]
+	line, l := ap.TruncateRightToFit(ds.prefix+strings.ReplaceAll(text, "\t", "    "), width)
+	ap.MoveCursor(ap.Margin, y)
+	ap.WriteString(ds.style + line + strings.Repeat(" ", width-l) + Reset)
+}
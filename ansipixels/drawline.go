@@ -0,0 +1,212 @@
+package ansipixels
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Half pixels of a screen cell.
+const (
+	topHalf    = 1
+	bottomHalf = 2
+)
+
+// cellPixels accumulates the half pixels (or their intensities, for antialiasing) of a drawing,
+// per screen cell, in the order cells are first touched.
+type cellPixels struct {
+	cells []cellPos
+	top   map[cellPos]float64
+	bot   map[cellPos]float64
+}
+
+type cellPos struct{ x, y int }
+
+func newCellPixels() *cellPixels {
+	return &cellPixels{top: make(map[cellPos]float64), bot: make(map[cellPos]float64)}
+}
+
+// Adds the half pixel x, y (y in half pixels) with intensity v, keeping the max for overlaps.
+func (cp *cellPixels) plot(x, y int, v float64) {
+	if x < 0 || y < 0 {
+		return // off screen (and y/2 would be wrong).
+	}
+	pos := cellPos{x, y / 2}
+	m := cp.top
+	if y%2 == 1 {
+		m = cp.bot
+	}
+	if _, found := cp.top[pos]; !found {
+		if _, found = cp.bot[pos]; !found {
+			cp.cells = append(cp.cells, pos)
+		}
+	}
+	m[pos] = max(m[pos], v)
+}
+
+// DrawLine draws a line from x0, y0 to x1, y1 using the Bresenham algorithm and half blocks:
+// y coordinates are in half cells (0 to 2*H-1), x in cells. The line is drawn with color
+// (e.g. [Red] or a 38;5 or 38;2 sequence); cells it crosses are overwritten (so the other half of
+// a cell the line only partially covers becomes the background). The line is clipped to the screen.
+func (ap *AnsiPixels) DrawLine(x0, y0, x1, y1 int, color string) {
+	cp := newCellPixels()
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		cp.plot(x0, y0, 1)
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+	ap.drawCells(cp, color)
+}
+
+// Draws the half pixels of cp with color.
+func (ap *AnsiPixels) drawCells(cp *cellPixels, color string) {
+	ap.WriteString(color)
+	for _, pos := range cp.cells {
+		if !ap.inScreen(pos) {
+			continue
+		}
+		ap.MoveCursor(pos.x, pos.y)
+		switch _, top := cp.top[pos]; {
+		case !top:
+			ap.WriteRune(BottomHalfPixel)
+		case cp.bot[pos] > 0:
+			ap.WriteRune(FullPixel)
+		default:
+			ap.WriteRune(TopHalfPixel)
+		}
+	}
+	ap.WriteString(Reset)
+}
+
+// DrawCircle draws the outline of a circle centered on cx, cy, in the same half cell coordinates
+// as [DrawLine] (so it looks round, with radius cells horizontally and radius half cells
+// vertically), using color. E.g. for gauges. The circle is clipped to the screen.
+func (ap *AnsiPixels) DrawCircle(cx, cy, radius int, color string) {
+	ap.DrawEllipse(cx, cy, radius, radius, color)
+}
+
+// DrawEllipse draws the outline of an ellipse centered on cx, cy, with rx and ry radii (in cells
+// horizontally and half cells vertically, like for [DrawLine]), using the midpoint algorithm.
+// The ellipse is clipped to the screen.
+func (ap *AnsiPixels) DrawEllipse(cx, cy, rx, ry int, color string) {
+	if rx < 0 || ry < 0 {
+		return
+	}
+	cp := newCellPixels()
+	plot4 := func(x, y int) {
+		cp.plot(cx+x, cy+y, 1)
+		cp.plot(cx-x, cy+y, 1)
+		cp.plot(cx+x, cy-y, 1)
+		cp.plot(cx-x, cy-y, 1)
+	}
+	rx2, ry2 := rx*rx, ry*ry
+	x, y := 0, ry
+	// Region 1, slope above -1: step x.
+	p := ry2 - rx2*ry + rx2/4
+	for ry2*x <= rx2*y {
+		plot4(x, y)
+		x++
+		if p < 0 {
+			p += 2*ry2*x + ry2
+		} else {
+			y--
+			p += 2*ry2*x - 2*rx2*y + ry2
+		}
+	}
+	// Region 2: step y.
+	p = ry2*x*x + ry2*x + rx2*(y-1)*(y-1) - rx2*ry2
+	for y >= 0 {
+		plot4(x, y)
+		y--
+		if p > 0 {
+			p += rx2 - 2*rx2*y
+		} else {
+			x++
+			p += 2*ry2*x - 2*rx2*y + rx2
+		}
+	}
+	ap.drawCells(cp, color)
+}
+
+// DrawLineAA draws an antialiased line (using the Xiaolin Wu algorithm) from x0, y0 to x1, y1,
+// in the same half cell coordinates as [DrawLine], with color fg blended onto bg according to
+// the coverage of each half cell. Cells crossed by the line are overwritten with the top and
+// bottom halves as foreground and background colors; true color is used if [TrueColor] is set,
+// the closest of the 216 colors otherwise.
+func (ap *AnsiPixels) DrawLineAA(x0, y0, x1, y1 float64, fg, bg color.RGBA) {
+	cp := newCellPixels()
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	plot := func(x, y, v float64) {
+		if steep {
+			x, y = y, x
+		}
+		cp.plot(int(math.Floor(x)), int(math.Floor(y)), v)
+	}
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	gradient := 1.
+	if x1 != x0 {
+		gradient = (y1 - y0) / (x1 - x0)
+	}
+	start := math.Round(x0)
+	y := y0 + gradient*(start-x0)
+	for x := start; x <= math.Round(x1); x++ {
+		yi := math.Floor(y)
+		f := y - yi
+		plot(x, yi, 1-f)
+		plot(x, yi+1, f)
+		y += gradient
+	}
+	for _, pos := range cp.cells {
+		if !ap.inScreen(pos) {
+			continue
+		}
+		top, bottom := blend(bg, fg, cp.top[pos]), blend(bg, fg, cp.bot[pos])
+		ap.MoveCursor(pos.x, pos.y)
+		ap.WriteString(ap.fgSequence(top) + ap.bgSequence(bottom))
+		ap.WriteRune(TopHalfPixel)
+	}
+	ap.WriteString(Reset)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func (ap *AnsiPixels) inScreen(pos cellPos) bool {
+	return pos.x >= 0 && pos.y >= 0 && pos.x < ap.W && pos.y < ap.H
+}
+
+// Returns the sequence to set the foreground color, see bgSequence.
+func (ap *AnsiPixels) fgSequence(fg color.RGBA) string {
+	if ap.TrueColor {
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", fg.R, fg.G, fg.B)
+	}
+	return fmt.Sprintf("\033[38;5;%dm", convertColorTo216(fg))
+}
@@ -0,0 +1,46 @@
+package ansipixels
+
+import (
+	"bufio"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestDrawLine(t *testing.T) {
+	sb := newScreenBuffer(8, 4)
+	ap := &AnsiPixels{W: 8, H: 4, Out: bufio.NewWriter(sb), snapshot: sb}
+	ap.DrawLine(0, 0, 7, 7, Red)  // diagonal, one half cell down per column.
+	ap.DrawLine(7, 0, 4, 0, Blue) // horizontal on the top halves.
+	ap.DrawLine(0, 7, 0, 4, "")   // vertical, full cells.
+	ap.DrawLine(-3, 7, 20, 7, "") // clipped, overwrites the full cell of the vertical line.
+	expected := "▀▄  ▀▀▀▀\n  ▀▄\n█   ▀▄\n▄▄▄▄▄▄▄▄\n"
+	if got := ap.Snapshot(); got != expected {
+		t.Errorf("unexpected line drawing:\n%s\nexpected:\n%s", got, expected)
+	}
+	sb = newScreenBuffer(4, 1)
+	var out strings.Builder
+	ap = &AnsiPixels{W: 4, H: 1, Out: bufio.NewWriter(&out), TrueColor: true}
+	ap.DrawLineAA(0, 0, 3, 1, color.RGBA{255, 255, 255, 255}, color.RGBA{A: 255})
+	_ = ap.Out.Flush()
+	got := out.String()
+	if !strings.Contains(got, "\033[38;2;255;255;255m\033[48;2;0;0;0m▀") || strings.Count(got, "▀") != 4 {
+		t.Errorf("unexpected antialiased line output %q", got)
+	}
+	_, _ = sb.Write([]byte(got))
+	if s := sb.String(); s != "▀▀▀▀\n" {
+		t.Errorf("unexpected antialiased line snapshot %q", s)
+	}
+}
+
+func TestDrawCircle(t *testing.T) {
+	sb := newScreenBuffer(16, 5)
+	ap := &AnsiPixels{W: 16, H: 5, Out: bufio.NewWriter(sb), snapshot: sb}
+	ap.DrawCircle(3, 4, 3, Green)
+	ap.DrawEllipse(12, 4, 3, 2, "")
+	ap.DrawCircle(15, 9, 2, "") // clipped.
+	expected := "  ▄▄▄\n▄▀   ▀▄   ▄▀▀▀▄\n█     █  ▀▄   ▄▀\n ▀▄▄▄▀     ▀▀▀▄▄\n             █\n"
+	if got := ap.Snapshot(); got != expected {
+		t.Errorf("unexpected circles:\n%s\nexpected:\n%s", got, expected)
+	}
+}
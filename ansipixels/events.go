@@ -0,0 +1,69 @@
+package ansipixels
+
+import (
+	"bytes"
+
+	"fortio.org/terminal"
+)
+
+// ReadEvents waits for input or a signal, like ReadOrResizeOrSignal, and returns the decoded
+// [terminal.Event]s (keys, mouse, paste, resize), the same event type as used by
+// terminal.Terminal.ReadEvent so input handling can be shared. On resize the new size is
+// already set in W and H but, unlike ReadOrResizeOrSignal, OnResize isn't called: the caller
+// handles the ResizeEvent. Other signals return a SignalEvent and terminal.ErrSignal.
+// ap.Data is set to the raw input read.
+func (ap *AnsiPixels) ReadEvents() ([]terminal.Event, error) {
+	ap.Do(ap.EndSyncMode)
+	if len(ap.pendingEv) > 0 {
+		events := ap.pendingEv
+		ap.pendingEv = nil
+		return events, nil
+	}
+	for {
+		select {
+		case s := <-ap.C:
+			if !ap.IsResizeSignal(s) {
+				return []terminal.Event{{Type: terminal.SignalEvent, Signal: s}}, terminal.ErrSignal
+			}
+			ap.resumeAfterStop(s)
+			if err := ap.GetSize(); err != nil {
+				return nil, err
+			}
+			return []terminal.Event{{Type: terminal.ResizeEvent, X: ap.W, Y: ap.H}}, nil
+		default:
+			n, err := ap.readInput()
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				continue // timeout.
+			}
+			ap.Data = ap.buf[0:n]
+			events, rest := terminal.DecodeEvents(append(ap.pendingIn, ap.Data...))
+			ap.pendingIn = bytes.Clone(rest)
+			if len(events) > 0 {
+				return events, nil
+			}
+		}
+	}
+}
+
+// ReadEvent blocks until the next complete event (key, mouse, paste, resize or signal) and returns
+// it. Events decoded from the same input are queued and returned by the next calls (or ReadEvents).
+// See [ReadEvents] for the details.
+func (ap *AnsiPixels) ReadEvent() (terminal.Event, error) {
+	if len(ap.pendingEv) == 0 {
+		events, err := ap.ReadEvents()
+		if err != nil {
+			var ev terminal.Event
+			if len(events) > 0 {
+				ev = events[0]
+			}
+			return ev, err
+		}
+		ap.pendingEv = events
+	}
+	ev := ap.pendingEv[0]
+	ap.pendingEv = ap.pendingEv[1:]
+	return ev, nil
+}
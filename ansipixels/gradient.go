@@ -0,0 +1,133 @@
+package ansipixels
+
+import (
+	"image/color"
+	"math"
+)
+
+// ColorSpace is the color space used to interpolate colors, see [GradientSpace].
+type ColorSpace int
+
+const (
+	// OKLabSpace interpolation is perceptually uniform (the default used by [Gradient]).
+	OKLabSpace ColorSpace = iota
+	// RGBSpace is the naive interpolation of the sRGB components (mid colors tend to look dull).
+	RGBSpace
+	// HSLSpace interpolates hue (the shortest way around the color wheel), saturation and lightness.
+	HSLSpace
+)
+
+// Gradient returns steps colors evenly spaced from from to to (inclusive), interpolated in the
+// OKLab color space, e.g. for progress bars or heatmaps.
+func Gradient(from, to color.RGBA, steps int) []color.RGBA {
+	return GradientSpace(from, to, steps, OKLabSpace)
+}
+
+// GradientSpace is like [Gradient] but interpolates in the given color space. The alpha channel
+// is interpolated linearly.
+func GradientSpace(from, to color.RGBA, steps int, space ColorSpace) []color.RGBA {
+	if steps <= 0 {
+		return nil
+	}
+	res := make([]color.RGBA, steps)
+	res[0] = from
+	if steps == 1 {
+		return res
+	}
+	res[steps-1] = to
+	for i := 1; i < steps-1; i++ {
+		res[i] = interpolate(from, to, float64(i)/float64(steps-1), space)
+	}
+	return res
+}
+
+// Returns the color at t (in [0,1]) between a and b.
+func interpolate(a, b color.RGBA, t float64, space ColorSpace) color.RGBA {
+	var c color.RGBA
+	switch space {
+	case RGBSpace:
+		c = color.RGBA{R: lerp8(a.R, b.R, t), G: lerp8(a.G, b.G, t), B: lerp8(a.B, b.B, t)}
+	case HSLSpace:
+		h1, s1, l1 := rgbToHSL(a)
+		h2, s2, l2 := rgbToHSL(b)
+		// Grays have no hue, use the other color's.
+		if s1 == 0 {
+			h1 = h2
+		}
+		if s2 == 0 {
+			h2 = h1
+		}
+		dh := h2 - h1
+		dh -= math.Round(dh) // shortest way around.
+		n := HSLToRGB(h1+dh*t-math.Floor(h1+dh*t), lerp(s1, s2, t), lerp(l1, l2, t))
+		c = color.RGBA{R: n.R, G: n.G, B: n.B}
+	default:
+		l1, a1, b1 := rgbToOKLab(a)
+		l2, a2, b2 := rgbToOKLab(b)
+		c = okLabToRGB(lerp(l1, l2, t), lerp(a1, a2, t), lerp(b1, b2, t))
+	}
+	c.A = lerp8(a.A, b.A, t)
+	return c
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+func lerp8(a, b uint8, t float64) uint8 {
+	return uint8(math.Round(lerp(float64(a), float64(b), t)))
+}
+
+// Converts to HSL, h, s and l in [0,1] (inverse of HSLToRGB).
+func rgbToHSL(c color.RGBA) (h, s, l float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC, minC := max(r, g, b), min(r, g, b)
+	l = (maxC + minC) / 2
+	d := maxC - minC
+	if d == 0 {
+		return 0, 0, l
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return h / 6, s, l
+}
+
+// Converts linear light (in [0,1]) back to an sRGB component, inverse of SrgbToLinear.
+func linearToSrgb(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(255 * min(max(v, 0), 1)))
+}
+
+// See https://bottosson.github.io/posts/oklab/
+func rgbToOKLab(c color.RGBA) (l, a, b float64) {
+	r, g, bl := SrgbToLinear(c.R), SrgbToLinear(c.G), SrgbToLinear(c.B)
+	lc := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*bl)
+	mc := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*bl)
+	sc := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*bl)
+	return 0.2104542553*lc + 0.7936177850*mc - 0.0040720468*sc,
+		1.9779984951*lc - 2.4285922050*mc + 0.4505937099*sc,
+		0.0259040371*lc + 0.7827717662*mc - 0.8086757660*sc
+}
+
+func okLabToRGB(l, a, b float64) color.RGBA {
+	lc := l + 0.3963377774*a + 0.2158037573*b
+	mc := l - 0.1055613458*a - 0.0638541728*b
+	sc := l - 0.0894841775*a - 1.2914855480*b
+	lc, mc, sc = lc*lc*lc, mc*mc*mc, sc*sc*sc
+	return color.RGBA{
+		R: linearToSrgb(4.0767416621*lc - 3.3077115913*mc + 0.2309699292*sc),
+		G: linearToSrgb(-1.2684380046*lc + 2.6097574011*mc - 0.3413193965*sc),
+		B: linearToSrgb(-0.0041960863*lc - 0.7034186147*mc + 1.7076904160*sc),
+	}
+}
@@ -0,0 +1,36 @@
+package ansipixels
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestGradient(t *testing.T) {
+	from := color.RGBA{200, 30, 60, 255}
+	to := color.RGBA{10, 120, 250, 128}
+	for _, space := range []ColorSpace{OKLabSpace, RGBSpace, HSLSpace} {
+		g := GradientSpace(from, to, 7, space)
+		if len(g) != 7 || g[0] != from || g[6] != to {
+			t.Errorf("space %d: bad endpoints or length: %v", space, g)
+		}
+		// Black to white ramp is monotonic in lightness.
+		prev := -1.
+		for i, c := range GradientSpace(color.RGBA{A: 255}, color.RGBA{255, 255, 255, 255}, 17, space) {
+			l := RelativeLuminance(c)
+			if l <= prev {
+				t.Errorf("space %d: step %d %v luminance %f not above previous %f", space, i, c, l, prev)
+			}
+			prev = l
+		}
+	}
+	if g := Gradient(from, to, 1); len(g) != 1 || g[0] != from {
+		t.Errorf("single step gradient: %v", g)
+	}
+	if g := Gradient(from, to, 0); g != nil {
+		t.Errorf("empty gradient: %v", g)
+	}
+	// OKLab mid gray is perceptually mid way (~#636363), much darker than the RGB one.
+	if mid := Gradient(color.RGBA{A: 255}, color.RGBA{255, 255, 255, 255}, 3)[1]; mid.R != mid.G || mid.R < 0x60 || mid.R > 0x66 {
+		t.Errorf("unexpected OKLab mid gray %v", mid)
+	}
+}
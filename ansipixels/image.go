@@ -1,7 +1,9 @@
 package ansipixels
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -9,6 +11,7 @@ import (
 	_ "image/jpeg" // Import JPEG decoder
 	_ "image/png"  // Import PNG decoder
 	"io"
+	"io/fs"
 	"os"
 	"time"
 
@@ -113,6 +116,84 @@ func (ap *AnsiPixels) Draw216ColorImage(sx, sy int, img *image.RGBA) error {
 	return err
 }
 
+// ANSI16Palette is the (xterm default) RGB values of the 16 basic ansi colors, in order:
+// 0-7 are black, red, green, yellow, blue, magenta, cyan, white (gray) and 8-15 their bright versions.
+var ANSI16Palette = []color.RGBA{
+	{0, 0, 0, 255}, {205, 0, 0, 255}, {0, 205, 0, 255}, {205, 205, 0, 255},
+	{0, 0, 238, 255}, {205, 0, 205, 255}, {0, 205, 205, 255}, {229, 229, 229, 255},
+	{127, 127, 127, 255}, {255, 0, 0, 255}, {0, 255, 0, 255}, {255, 255, 0, 255},
+	{92, 92, 255, 255}, {255, 0, 255, 255}, {0, 255, 255, 255}, {255, 255, 255, 255},
+}
+
+// DrawImagePalette draws the image using only the colors of palette (nearest match), with
+// Floyd-Steinberg dithering if dither is true. E.g. with [ANSI16Palette] for terminals (or logs)
+// limited to 16 colors. In TrueColor mode the palette colors are output as is, otherwise the
+// first 16 entries are output as the 16 basic ansi colors (so the palette should be in that
+// order) and the others with their closest 216 colors.
+func (ap *AnsiPixels) DrawImagePalette(sx, sy int, img *image.RGBA, palette []color.RGBA, dither bool) error {
+	if len(palette) == 0 {
+		return errors.New("empty palette")
+	}
+	pal := make(color.Palette, len(palette))
+	for i, c := range palette {
+		pal[i] = c
+	}
+	b := img.Bounds()
+	pimg := image.NewPaletted(b, pal)
+	if dither {
+		draw.FloydSteinberg.Draw(pimg, b, img, b.Min)
+	} else {
+		draw.Draw(pimg, b, img, b.Min, draw.Src)
+	}
+	for y := b.Min.Y; y < b.Max.Y; y += 2 {
+		prevFg, prevBg := -1, -1
+		ap.WriteAtStr(sx, sy, Reset)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// Lower half pixel, see Draw216ColorImage: foreground is the bottom pixel.
+			fg := int(pimg.ColorIndexAt(x, y+1))
+			bg := int(pimg.ColorIndexAt(x, y))
+			if y+1 >= b.Max.Y {
+				fg = bg
+			}
+			if fg != prevFg {
+				ap.WriteString(ap.paletteColor(palette, fg, false))
+			}
+			if bg != prevBg {
+				ap.WriteString(ap.paletteColor(palette, bg, true))
+			}
+			ap.WriteRune('▄')
+			prevFg, prevBg = fg, bg
+		}
+		sy++
+	}
+	ap.WriteString(Reset)
+	return nil
+}
+
+// Returns the sequence setting the foreground (or background) to entry idx of the palette.
+func (ap *AnsiPixels) paletteColor(palette []color.RGBA, idx int, background bool) string {
+	c := palette[idx]
+	switch {
+	case ap.TrueColor && background:
+		return fmt.Sprintf("\033[48;2;%d;%d;%dm", c.R, c.G, c.B)
+	case ap.TrueColor:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B)
+	case idx < 16:
+		code := 30 + idx
+		if idx >= 8 {
+			code = 90 + idx - 8
+		}
+		if background {
+			code += 10
+		}
+		return fmt.Sprintf("\033[%dm", code)
+	case background:
+		return fmt.Sprintf("\033[48;5;%dm", convertColorTo216(c))
+	default:
+		return fmt.Sprintf("\033[38;5;%dm", convertColorTo216(c))
+	}
+}
+
 func (ap *AnsiPixels) DrawMonoImage(sx, sy int, img *image.Gray, color string) error {
 	ap.WriteAtStr(sx, sy, color)
 	threshold := uint8(127)
@@ -166,27 +247,90 @@ func toGrey(rgbaImg *image.RGBA, img image.Image) {
 	}
 }
 
-func resizeAndCenter(img *image.RGBA, maxW, maxH int, zoom float64, offsetX, offsetY int) *image.RGBA {
-	// Get original image dimensions
-	origBounds := img.Bounds()
-	origW := origBounds.Dx()
-	origH := origBounds.Dy()
-
+// scaledSize returns the dimensions of an origW x origH image scaled (preserving aspect ratio)
+// to fit within maxW x maxH and then multiplied by zoom.
+func scaledSize(origW, origH, maxW, maxH int, zoom float64) (int, int) {
 	// Calculate aspect ratio scaling
 	scaleW := float64(maxW) / float64(origW)
 	scaleH := float64(maxH) / float64(origH)
 	scale := min(scaleW, scaleH) // Choose the smallest scale to fit within bounds
 	scale *= zoom
+	return int(float64(origW) * scale), int(float64(origH) * scale)
+}
+
+// clampOffset limits the pan offset so at least half of the image (or half of the screen
+// when the image is larger than the screen) remains visible.
+func clampOffset(offset, newSize, maxSize int) int {
+	visible := max(1, min(newSize, maxSize)/2)
+	center := (maxSize - newSize) / 2
+	// final position is center+offset and must be within [visible-newSize, maxSize-visible].
+	return min(max(offset, visible-newSize-center), maxSize-visible-center)
+}
+
+// ClampOffsets returns the offsetX, offsetY adjusted so that panning can't move the image
+// (as it would be displayed by ShowImage with the same zoom) entirely off screen.
+func (ap *AnsiPixels) ClampOffsets(img *Image, zoom float64, offsetX, offsetY int) (int, int) {
+	maxW, maxH := ap.W-2*ap.Margin, 2*ap.H-4*ap.Margin
+	newW, newH := scaledSize(img.Width, img.Height, maxW, maxH, zoom)
+	return clampOffset(offsetX, newW, maxW), clampOffset(offsetY, newH, maxH)
+}
+
+// FitZoom returns the zoom factor to use with ShowImage so the image fills the available
+// width (if fitWidth is true) or height (otherwise), instead of the default fit within both.
+func (ap *AnsiPixels) FitZoom(img *Image, fitWidth bool) float64 {
+	maxW, maxH := ap.W-2*ap.Margin, 2*ap.H-4*ap.Margin
+	scaleW := float64(maxW) / float64(img.Width)
+	scaleH := float64(maxH) / float64(img.Height)
+	if fitWidth {
+		return scaleW / min(scaleW, scaleH)
+	}
+	return scaleH / min(scaleW, scaleH)
+}
+
+// ActualSizeZoom returns the zoom factor to use with ShowImage so each image pixel maps
+// to exactly one terminal (half block) pixel.
+func (ap *AnsiPixels) ActualSizeZoom(img *Image) float64 {
+	maxW, maxH := ap.W-2*ap.Margin, 2*ap.H-4*ap.Margin
+	return 1. / min(float64(maxW)/float64(img.Width), float64(maxH)/float64(img.Height))
+}
+
+// Rotate90 rotates (all the frames of) the image by 90 degrees clockwise.
+func (img *Image) Rotate90() {
+	for i, src := range img.Images {
+		b := src.Bounds()
+		dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				dst.SetRGBA(b.Max.Y-1-y, x-b.Min.X, src.RGBAAt(x, y))
+			}
+		}
+		img.Images[i] = dst
+	}
+	img.Width, img.Height = img.Height, img.Width
+}
+
+// ImageColorAt returns the color of the (first frame of the) image, as displayed by ShowImage with
+// the same zoom and offsets, at the x, y (0 based) screen position (top half of that character cell).
+func (ap *AnsiPixels) ImageColorAt(img *Image, zoom float64, offsetX, offsetY, x, y int) color.RGBA {
+	canvas := resizeAndCenter(img.Images[0], ap.W-2*ap.Margin, 2*ap.H-4*ap.Margin, zoom, offsetX, offsetY, ap.LetterboxColor)
+	return canvas.RGBAAt(x-ap.Margin, 2*(y-ap.Margin))
+}
+
+func resizeAndCenter(img *image.RGBA, maxW, maxH int, zoom float64, offsetX, offsetY int, fill color.RGBA) *image.RGBA {
+	// Get original image dimensions
+	origBounds := img.Bounds()
 
 	// Calculate new dimensions while preserving aspect ratio
-	newW := int(float64(origW) * scale)
-	newH := int(float64(origH) * scale)
+	newW, newH := scaledSize(origBounds.Dx(), origBounds.Dy(), maxW, maxH, zoom)
 
 	canvas := image.NewRGBA(image.Rect(0, 0, maxW, maxH))
+	if fill.A != 0 {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(fill), image.Point{}, draw.Src)
+	}
 
-	// Calculate the offset to center the image
-	offsetX += (maxW - newW) / 2
-	offsetY += (maxH - newH) / 2
+	// Calculate the offset to center the image, not letting it go off screen.
+	offsetX = clampOffset(offsetX, newW, maxW) + (maxW-newW)/2
+	offsetY = clampOffset(offsetY, newH, maxH) + (maxH-newH)/2
 
 	// Resize the image
 	resized := image.NewRGBA(image.Rect(0, 0, newW, newH))
@@ -214,6 +358,17 @@ func (ap *AnsiPixels) ReadImage(path string) (*Image, error) {
 	return ap.DecodeImage(file)
 }
 
+// ReadImageFS is like [ReadImage] but reads path from fsys, e.g. an embed.FS to ship
+// image assets inside the binary.
+func (ap *AnsiPixels) ReadImageFS(fsys fs.FS, path string) (*Image, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ap.DecodeImage(file)
+}
+
 type Image struct {
 	Format string
 	Width  int
@@ -222,34 +377,46 @@ type Image struct {
 	Delays []int
 }
 
+var gifMagic = []byte("GIF8")
+
+// DecodeImage decodes directly from the reader (buffered) instead of reading the whole
+// content in memory first. Gif are detected upfront so their frames are decoded in a single pass.
 func (ap *AnsiPixels) DecodeImage(inp io.Reader) (*Image, error) {
-	// Automatically detect and decode the image format
-	all, err := io.ReadAll(inp)
-	if err != nil {
+	br := bufio.NewReader(inp)
+	magic, err := br.Peek(len(gifMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
 		return nil, err
 	}
-	data := bytes.NewReader(all)
-	img, format, err := image.Decode(data)
+	if bytes.Equal(magic, gifMagic) {
+		return decodeGif(br)
+	}
+	// Automatically detect and decode the image format
+	img, format, err := image.Decode(br)
 	if err != nil {
 		return nil, err
 	}
 	log.Debugf("Image format: %s", format)
-	res := &Image{
+	return &Image{
 		Format: format,
 		Width:  img.Bounds().Dx(),
 		Height: img.Bounds().Dy(),
-	}
-	if format != "gif" {
-		res.Images = []*image.RGBA{convertToRGBA(img)}
-		return res, nil
-	}
-	data = bytes.NewReader(all)
-	gifImages, err := gif.DecodeAll(data)
+		Images: []*image.RGBA{convertToRGBA(img)},
+	}, nil
+}
+
+func decodeGif(inp io.Reader) (*Image, error) {
+	gifImages, err := gif.DecodeAll(inp)
 	if err != nil {
 		return nil, err
 	}
-	res.Images = make([]*image.RGBA, 0, len(gifImages.Image))
+	log.Debugf("Image format: gif (%d frames)", len(gifImages.Image))
 	bounds := gifImages.Image[0].Bounds()
+	res := &Image{
+		Format: "gif",
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Images: make([]*image.RGBA, 0, len(gifImages.Image)),
+	}
 	current := image.NewRGBA(bounds)
 	for _, frame := range gifImages.Image {
 		// TODO use Disposal[i] correctly.
@@ -264,33 +431,90 @@ func (ap *AnsiPixels) DecodeImage(inp io.Reader) (*Image, error) {
 }
 
 // Color string is the fallback mono color to use when AnsiPixels.TrueColor is false.
+// Animated images are played once, blocking (sleeping) between frames, see [ShowOneFrame]
+// to drive the playback from an event loop instead.
 func (ap *AnsiPixels) ShowImage(imagesRGBA *Image, zoom float64, offsetX, offsetY int, colorString string) error {
 	// GetSize done in Open and Resize handler.
-	for i, imgRGBA := range imagesRGBA.Images {
-		img := resizeAndCenter(imgRGBA, ap.W-2*ap.Margin, 2*ap.H-4*ap.Margin, zoom, offsetX, offsetY)
-		if ap.Gray {
-			toGrey(img, img)
-		}
-		var err error
-		switch {
-		case ap.TrueColor:
-			err = ap.DrawTrueColorImage(ap.Margin, ap.Margin, img)
-		case ap.Color:
-			err = ap.Draw216ColorImage(ap.Margin, ap.Margin, img)
-		default:
-			err = ap.DrawMonoImage(ap.Margin, ap.Margin, grayScaleImage(img), colorString)
-		}
-		if err != nil {
+	for i := range imagesRGBA.Images {
+		if err := ap.ShowOneFrame(imagesRGBA, i, zoom, offsetX, offsetY, colorString); err != nil {
 			return err
 		}
 		ap.Out.Flush()
 		if i < len(imagesRGBA.Delays)-1 { // maybe read keyboard/signal for stop request in case this is longish.
-			delay := imagesRGBA.Delays[i]
-			log.Debugf("Delay %d", delay)
+			delay := imagesRGBA.DelayAt(i)
+			log.Debugf("Delay %v", delay)
 			if delay > 0 {
-				time.Sleep(time.Duration(delay) * 10 * time.Millisecond)
+				time.Sleep(delay)
 			}
 		}
 	}
 	return nil
 }
+
+// ShowOneFrame draws frame i (modulo the number of frames) of the image, like ShowImage does
+// for each frame but without flushing nor waiting, e.g. to play animations from the app's event
+// loop using [Image.DelayAt] as read timeout and stay responsive to keys and resizes.
+func (ap *AnsiPixels) ShowOneFrame(img *Image, i int, zoom float64, offsetX, offsetY int, colorString string) error {
+	frame := img.FrameAt(i)
+	if frame == nil {
+		return nil
+	}
+	cw, ch := ap.imageCellSize()
+	canvas := resizeAndCenter(frame, (ap.W-2*ap.Margin)*cw, (ap.H-2*ap.Margin)*ch, zoom,
+		offsetX*cw, offsetY*ch/2, ap.LetterboxColor)
+	return ap.drawImage(ap.Margin, ap.Margin, canvas, colorString)
+}
+
+// NumFrames returns the number of frames, 1 for still images.
+func (img *Image) NumFrames() int {
+	return len(img.Images)
+}
+
+// FrameAt returns frame i, modulo the number of frames so animations can loop (nil if the
+// image has no frame).
+func (img *Image) FrameAt(i int) *image.RGBA {
+	n := len(img.Images)
+	if n == 0 {
+		return nil
+	}
+	return img.Images[(i%n+n)%n]
+}
+
+// DelayAt returns how long frame i (modulo the number of frames) should be shown before
+// the next one, 0 for still images or when not specified.
+func (img *Image) DelayAt(i int) time.Duration {
+	n := len(img.Delays)
+	if n == 0 {
+		return 0
+	}
+	return time.Duration(img.Delays[(i%n+n)%n]) * 10 * time.Millisecond
+}
+
+// DrawImageInRect draws the (first frame of the) image scaled to fit and centered in the
+// w x h characters rectangle at x, y. Useful for thumbnails.
+func (ap *AnsiPixels) DrawImageInRect(img *Image, x, y, w, h int, colorString string) error {
+	if w <= 0 || h <= 0 || len(img.Images) == 0 {
+		return nil
+	}
+	cw, ch := ap.imageCellSize()
+	return ap.drawImage(x, y, resizeAndCenter(img.Images[0], w*cw, h*ch, 1., 0, 0, ap.LetterboxColor), colorString)
+}
+
+// Draws an already resized image at sx, sy according to the color mode.
+func (ap *AnsiPixels) drawImage(sx, sy int, img *image.RGBA, colorString string) error {
+	if ap.Gray {
+		toGrey(img, img)
+	}
+	switch {
+	case ap.KittyGraphics:
+		return ap.DrawKittyImage(sx, sy, img)
+	case ap.Sixel:
+		return ap.DrawSixelImage(sx, sy, img)
+	case ap.TrueColor:
+		return ap.DrawTrueColorImage(sx, sy, img)
+	case ap.Color:
+		return ap.Draw216ColorImage(sx, sy, img)
+	default:
+		return ap.DrawMonoImage(sx, sy, grayScaleImage(img), colorString)
+	}
+}
@@ -0,0 +1,78 @@
+package ansipixels
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+
+	"fortio.org/terminal"
+)
+
+// Mouse click at 10,5 then 'q'.
+var scriptedInput = [][]byte{[]byte("\033[M *%"), []byte("q")}
+
+func TestScriptedInput(t *testing.T) {
+	ap := &AnsiPixels{Out: bufio.NewWriter(io.Discard), C: make(chan os.Signal)}
+	ap.SetInput(terminal.NewScriptedReader(0, scriptedInput...))
+	if err := ap.ReadOrResizeOrSignal(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !ap.LeftClick() || ap.Mx != 10 || ap.My != 5 {
+		t.Errorf("expected left click at 10,5, got %v %d,%d", ap.Mouse, ap.Mx, ap.My)
+	}
+	if err := ap.ReadOrResizeOrSignal(); err != nil || string(ap.Data) != "q" {
+		t.Errorf("expected q, got %q %v", ap.Data, err)
+	}
+	if err := ap.ReadOrResizeOrSignal(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF at end of script, got %v", err)
+	}
+}
+
+func BenchmarkScriptedInput(b *testing.B) {
+	ap := &AnsiPixels{Out: bufio.NewWriter(io.Discard), C: make(chan os.Signal)}
+	sr := terminal.NewScriptedReader(0, scriptedInput...)
+	ap.SetInput(sr)
+	for range b.N {
+		sr.Rewind()
+		for {
+			if err := ap.ReadOrResizeOrSignal(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	ap := &AnsiPixels{Out: bufio.NewWriter(io.Discard), C: make(chan os.Signal)}
+	// SGR mouse event split across 2 reads, then a key and an arrow.
+	ap.SetInput(terminal.NewScriptedReader(0, []byte("\033[<0;12"), []byte(";7mx\033[A")))
+	events, err := ap.ReadEvents()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := []terminal.Event{
+		{Type: terminal.MouseEvent, X: 12, Y: 7, Buttons: MouseRelease},
+		{Type: terminal.KeyEvent, Key: 'x'},
+		{Type: terminal.KeyEvent, Key: terminal.KeyUp},
+	}
+	if !slices.Equal(events, expected) {
+		t.Errorf("got %+v, expected %+v", events, expected)
+	}
+}
+
+func TestReadEvent(t *testing.T) {
+	ap := &AnsiPixels{Out: bufio.NewWriter(io.Discard), C: make(chan os.Signal)}
+	ap.SetInput(terminal.NewScriptedReader(0, []byte("ab\033["), []byte("B")))
+	for _, expected := range []rune{'a', 'b', terminal.KeyDown} {
+		ev, err := ap.ReadEvent()
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if ev.Type != terminal.KeyEvent || ev.Key != expected {
+			t.Errorf("got %+v, expected key %q", ev, expected)
+		}
+	}
+}
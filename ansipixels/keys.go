@@ -0,0 +1,70 @@
+package ansipixels
+
+import (
+	"fmt"
+	"unicode"
+
+	"fortio.org/terminal"
+)
+
+var specialKeyNames = map[rune]string{
+	terminal.KeyUp:       "Up",
+	terminal.KeyDown:     "Down",
+	terminal.KeyLeft:     "Left",
+	terminal.KeyRight:    "Right",
+	terminal.KeyHome:     "Home",
+	terminal.KeyEnd:      "End",
+	terminal.KeyPageUp:   "PageUp",
+	terminal.KeyPageDown: "PageDown",
+	terminal.KeyInsert:   "Insert",
+	terminal.KeyDelete:   "Delete",
+	terminal.KeyEscape:   "Esc",
+	'\t':                 "Tab",
+	'\r':                 "Enter",
+	' ':                  "Space",
+	127:                  "Backspace",
+}
+
+// KeyName returns a readable name for the (first) key in data, typically ap.Data, e.g. "q", "Ctrl-C",
+// "Up", "F5" or "Alt-x", for on screen hints and debugging input. Mouse events and pastes are
+// "Mouse" and "Paste".
+func KeyName(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if len(data) >= 2 && data[0] == terminal.KeyEscape && data[1] != '[' && data[1] != 'O' {
+		return "Alt-" + KeyName(data[1:])
+	}
+	events, rest := terminal.DecodeEvents(data)
+	if len(events) == 0 {
+		return fmt.Sprintf("Incomplete %q", rest)
+	}
+	ev := events[0]
+	switch ev.Type {
+	case terminal.MouseEvent:
+		return "Mouse"
+	case terminal.PasteEvent:
+		return "Paste"
+	case terminal.KeyEvent:
+		// handled below.
+	default:
+		return ""
+	}
+	k := ev.Key
+	if name, ok := specialKeyNames[k]; ok {
+		return name
+	}
+	switch {
+	case k >= terminal.KeyF1 && k <= terminal.KeyF12:
+		return fmt.Sprintf("F%d", k-terminal.KeyF1+1)
+	case k == terminal.KeyUnknown:
+		return fmt.Sprintf("Unknown %q", data)
+	case k == 0:
+		return "Ctrl-Space"
+	case k < ' ':
+		return "Ctrl-" + string('A'+k-1)
+	case unicode.IsPrint(k):
+		return string(k)
+	}
+	return fmt.Sprintf("%U", k)
+}
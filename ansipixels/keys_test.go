@@ -0,0 +1,28 @@
+package ansipixels
+
+import "testing"
+
+func TestKeyName(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"q", "q"},
+		{"\x03", "Ctrl-C"},
+		{"\033[A", "Up"},
+		{"\033OQ", "F2"},
+		{"\033[15~", "F5"},
+		{"\033[24~", "F12"},
+		{"\033x", "Alt-x"},
+		{"\033", "Esc"},
+		{"\r", "Enter"},
+		{"\x7f", "Backspace"},
+		{"\033[M #$", "Mouse"},
+		{"é", "é"},
+	}
+	for _, tt := range tests {
+		if got := KeyName([]byte(tt.data)); got != tt.expected {
+			t.Errorf("KeyName(%q) = %q, expected %q", tt.data, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ansipixels
+
+import (
+	"encoding/base64"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+)
+
+const (
+	// Max size of the base64 payload of each kitty graphics escape sequence.
+	kittyChunkSize = 4096
+	// Id of the image shown by DrawKittyImage, replaced on each call.
+	kittyImageID = 1
+)
+
+// DetectKittyGraphics returns whether the terminal is known to support the kitty graphics
+// protocol, from its TERM (kitty and ghostty), e.g. to set [KittyGraphics] by default.
+func DetectKittyGraphics() bool {
+	tenv := os.Getenv("TERM")
+	return strings.Contains(tenv, "kitty") || strings.Contains(tenv, "ghostty")
+}
+
+// DrawKittyImage draws the image, at full pixel resolution, with its top left corner at the
+// sx, sy cell using the kitty graphics protocol (sending the RGBA pixels as is). The image
+// previously drawn by DrawKittyImage, if any, is deleted first, so animations don't pile up
+// images in the terminal.
+func (ap *AnsiPixels) DrawKittyImage(sx, sy int, img *image.RGBA) error {
+	ap.DeleteKittyImage()
+	ap.MoveCursor(sx, sy)
+	var sb strings.Builder
+	encodeKitty(&sb, img, kittyImageID)
+	_, err := ap.Out.WriteString(sb.String())
+	ap.kittyShown = true
+	return err
+}
+
+// DeleteKittyImage deletes (from the screen and the terminal memory) the image drawn by
+// [DrawKittyImage], if any.
+func (ap *AnsiPixels) DeleteKittyImage() {
+	if !ap.kittyShown {
+		return
+	}
+	ap.WriteString(fmt.Sprintf("\033_Ga=d,d=I,i=%d,q=2\033\\", kittyImageID))
+	ap.kittyShown = false
+}
+
+// Writes the kitty graphics sequences transmitting and displaying img (as 32 bits RGBA) with
+// the given id, the base64 payload being split in chunks.
+func encodeKitty(sb *strings.Builder, img *image.RGBA, id int) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	pix := img.Pix
+	if img.Stride != 4*w || len(pix) != 4*w*h { // sub image, copy the rows.
+		pix = make([]byte, 0, 4*w*h)
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			start := img.PixOffset(b.Min.X, y)
+			pix = append(pix, img.Pix[start:start+4*w]...)
+		}
+	}
+	payload := base64.StdEncoding.EncodeToString(pix)
+	first := true
+	for first || payload != "" {
+		chunk := payload[:min(kittyChunkSize, len(payload))]
+		payload = payload[len(chunk):]
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			// C=1: don't move the cursor, q=2: no responses.
+			fmt.Fprintf(sb, "\033_Ga=T,f=32,s=%d,v=%d,i=%d,C=1,q=2,m=%d;%s\033\\", w, h, id, more, chunk)
+			first = false
+			continue
+		}
+		fmt.Fprintf(sb, "\033_Gm=%d;%s\033\\", more, chunk)
+	}
+}
@@ -0,0 +1,57 @@
+package ansipixels
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var kittyRE = regexp.MustCompile("\033_G([^;]*);([^\033]*)\033\\\\")
+
+func TestEncodeKitty(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 30)) // 4800 bytes, 6400 in base64: 2 chunks.
+	for y := range 30 {
+		for x := range 40 {
+			img.Set(x, y, color.RGBA{uint8(x * 6), uint8(y * 8), uint8(x + y), 255})
+		}
+	}
+	for _, src := range []*image.RGBA{img, img.SubImage(image.Rect(3, 4, 13, 9)).(*image.RGBA)} {
+		var sb strings.Builder
+		encodeKitty(&sb, src, 1)
+		matches := kittyRE.FindAllStringSubmatch(sb.String(), -1)
+		if len(matches) == 0 || strings.Join(kittyRE.Split(sb.String(), -1), "") != "" {
+			t.Fatalf("unexpected kitty output %q", sb.String())
+		}
+		b := src.Bounds()
+		size := fmt.Sprintf(",s=%d,v=%d,", b.Dx(), b.Dy())
+		if !strings.HasPrefix(matches[0][1], "a=T,f=32,") || !strings.Contains(matches[0][1], size) {
+			t.Errorf("unexpected first chunk keys %q", matches[0][1])
+		}
+		var payload string
+		for i, m := range matches {
+			if last := i == len(matches)-1; strings.HasSuffix(m[1], "m=0") != last {
+				t.Errorf("chunk %d/%d has wrong continuation key: %q", i, len(matches), m[1])
+			}
+			payload += m[2]
+		}
+		pix, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			t.Fatalf("payload decoding error: %v", err)
+		}
+		var expected []byte
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c := src.RGBAAt(x, y)
+				expected = append(expected, c.R, c.G, c.B, c.A)
+			}
+		}
+		if !bytes.Equal(pix, expected) {
+			t.Errorf("decoded pixels differ from the source (%d vs %d bytes)", len(pix), len(expected))
+		}
+	}
+}
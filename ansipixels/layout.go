@@ -0,0 +1,81 @@
+package ansipixels
+
+import "math"
+
+// Layout helpers splitting a panel into sub panels. Panels don't track the screen size so
+// layouts should be (re)computed from [AnsiPixels.ScreenPanel] in OnResize, e.g.
+//
+//	header, rest := ap.ScreenPanel().SplitTop(1)
+//	body, footer := rest.SplitTop(rest.H - 1)
+//	left, right := body.SplitH(0.3)
+
+// SplitH splits the panel horizontally into a left and a right panel, side by side,
+// the left one taking ratio (0 to 1) of the width.
+func (p *Panel) SplitH(ratio float64) (left, right *Panel) {
+	return p.SplitLeft(ratioOf(p.W, ratio))
+}
+
+// SplitV splits the panel vertically into a top and a bottom panel, the top one
+// taking ratio (0 to 1) of the height.
+func (p *Panel) SplitV(ratio float64) (top, bottom *Panel) {
+	return p.SplitTop(ratioOf(p.H, ratio))
+}
+
+// SplitLeft splits the panel into a w columns wide left panel and the remaining right panel.
+func (p *Panel) SplitLeft(w int) (left, right *Panel) {
+	w = min(max(w, 0), p.W)
+	return p.Sub(0, 0, w, p.H), p.Sub(w, 0, p.W-w, p.H)
+}
+
+// SplitTop splits the panel into a h lines tall top panel (e.g. a header) and the remaining
+// bottom panel.
+func (p *Panel) SplitTop(h int) (top, bottom *Panel) {
+	h = min(max(h, 0), p.H)
+	return p.Sub(0, 0, p.W, h), p.Sub(0, h, p.W, p.H-h)
+}
+
+// Grid splits the panel into cols x rows panels of (as) equal (as possible) sizes, returned
+// row by row (left to right, top to bottom).
+func (p *Panel) Grid(cols, rows int) []*Panel {
+	if cols <= 0 || rows <= 0 {
+		return nil
+	}
+	res := make([]*Panel, 0, cols*rows)
+	for r := range rows {
+		y0, y1 := r*p.H/rows, (r+1)*p.H/rows
+		for c := range cols {
+			x0, x1 := c*p.W/cols, (c+1)*p.W/cols
+			res = append(res, p.Sub(x0, y0, x1-x0, y1-y0))
+		}
+	}
+	return res
+}
+
+func ratioOf(size int, ratio float64) int {
+	return int(math.Round(float64(size) * min(max(ratio, 0), 1)))
+}
+
+// Pct returns the screen position at xFrac and yFrac (0 to 1) of the width and height,
+// clamped to the screen, e.g. Pct(0.5, 0.5) is the center.
+func (ap *AnsiPixels) Pct(xFrac, yFrac float64) (x, y int) {
+	return min(ratioOf(ap.W, xFrac), ap.W-1), min(ratioOf(ap.H, yFrac), ap.H-1)
+}
+
+// MoveCursorPct moves the cursor to xFrac, yFrac (0 to 1) of the screen, see [Pct].
+func (ap *AnsiPixels) MoveCursorPct(xFrac, yFrac float64) {
+	ap.MoveCursor(ap.Pct(xFrac, yFrac))
+}
+
+// CenteredPanel returns a panel centered on the screen taking wFrac and hFrac (0 to 1) of
+// the screen width and height but at least minW x minH (if the screen is big enough).
+func (ap *AnsiPixels) CenteredPanel(wFrac, hFrac float64, minW, minH int) *Panel {
+	return ap.ScreenPanel().Centered(wFrac, hFrac, minW, minH)
+}
+
+// Centered returns a sub panel centered in p taking wFrac and hFrac (0 to 1) of its width
+// and height but at least minW x minH (clipped to p).
+func (p *Panel) Centered(wFrac, hFrac float64, minW, minH int) *Panel {
+	w := min(max(ratioOf(p.W, wFrac), minW), p.W)
+	h := min(max(ratioOf(p.H, hFrac), minH), p.H)
+	return p.Sub((p.W-w)/2, (p.H-h)/2, w, h)
+}
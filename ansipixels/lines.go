@@ -8,53 +8,6 @@ import (
 	"fortio.org/safecast"
 )
 
-// HSLToRGB converts HSL values to RGB. h, s and l in [0,1].
-// Initially from grol.io/grol/extensions images.go.
-func HSLToRGB(h, s, l float64) color.NRGBA {
-	var r, g, b float64
-
-	if s == 0 {
-		r, g, b = l, l, l
-	} else {
-		var q float64
-		if l < 0.5 {
-			q = l * (1. + s)
-		} else {
-			q = l + s - l*s
-		}
-		p := 2*l - q
-		r = hueToRGB(p, q, h+1/3.)
-		g = hueToRGB(p, q, h)
-		b = hueToRGB(p, q, h-1/3.)
-	}
-
-	return color.NRGBA{
-		R: uint8(math.Round(r * 255)),
-		G: uint8(math.Round(g * 255)),
-		B: uint8(math.Round(b * 255)),
-		A: 255,
-	}
-}
-
-func hueToRGB(p, q, t float64) float64 {
-	if t < 0 {
-		t += 1.
-	}
-	if t > 1 {
-		t -= 1.
-	}
-	if t < 1/6. {
-		return p + (q-p)*6*t
-	}
-	if t < 0.5 {
-		return q
-	}
-	if t < 2/3. {
-		return p + (q-p)*(2/3.-t)*6
-	}
-	return p
-}
-
 // Merge (additively) the pixel with alpha on top of the existing image.
 //
 //nolint:gosec // gosec unable to see the range checks with min/max.
@@ -201,3 +154,48 @@ func DrawLine(img *image.NRGBA, x0, y0, x1, y1 float64, c color.NRGBA) {
 		}
 	}
 }
+
+// DrawWedge fills the circular sector (pie slice) of center cx, cy and radius r going clockwise
+// from startDeg to endDeg, with angles in degrees and 0 at the top (12 o'clock) so it can be
+// directly used for circular gauges and progress indicators. A sweep of 360 degrees or more is a
+// full disc. Edges are anti-aliased by sampling coverage within each pixel (blended with
+// [MergePlot]).
+func DrawWedge(img *image.NRGBA, cx, cy, r, startDeg, endDeg float64, c color.NRGBA) {
+	const sub = 4 // sub samples per pixel in each direction.
+	sweep := endDeg - startDeg
+	if sweep <= 0 || r <= 0 {
+		return
+	}
+	start := math.Mod(startDeg, 360)
+	if start < 0 {
+		start += 360
+	}
+	inside := func(x, y float64) bool {
+		dx, dy := x-cx, y-cy
+		if dx*dx+dy*dy > r*r {
+			return false
+		}
+		if sweep >= 360 {
+			return true
+		}
+		a := math.Atan2(dx, -dy) * 180 / math.Pi // clockwise from the top.
+		a = math.Mod(a-start+720, 360)
+		return a <= sweep
+	}
+	b := img.Bounds()
+	x0, x1 := max(b.Min.X, int(math.Floor(cx-r))), min(b.Max.X-1, int(math.Ceil(cx+r)))
+	y0, y1 := max(b.Min.Y, int(math.Floor(cy-r))), min(b.Max.Y-1, int(math.Ceil(cy+r)))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			n := 0
+			for j := range sub {
+				for i := range sub {
+					if inside(float64(x)+(float64(i)+0.5)/sub, float64(y)+(float64(j)+0.5)/sub) {
+						n++
+					}
+				}
+			}
+			MergePlot(img, x, y, c, float64(n)/(sub*sub))
+		}
+	}
+}
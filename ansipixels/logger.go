@@ -0,0 +1,119 @@
+package ansipixels
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"fortio.org/log"
+)
+
+// CRLFWriter adds the \r needed before each \n for output to display correctly in raw mode.
+type CRLFWriter struct {
+	Out io.Writer
+}
+
+var (
+	lf   = []byte{'\n'}
+	crlf = []byte{'\r', '\n'}
+)
+
+func (w *CRLFWriter) Write(buf []byte) (int, error) {
+	_, err := w.Out.Write(bytes.ReplaceAll(buf, lf, crlf))
+	if err != nil {
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// SetLogSink redirects fortio.org/log (and thus stdlib "log") output to w, for instance
+// a file while drawing, regardless of whether stderr is redirected or not.
+// Passing nil (re)enables logging to the terminal (stderr) with the \r needed in raw mode.
+func (ap *AnsiPixels) SetLogSink(w io.Writer) {
+	// Keep same color logic as fortio logger, so flags like -logger-no-color work.
+	colormode := log.ColorMode()
+	if w == nil {
+		w = &CRLFWriter{Out: os.Stderr}
+	}
+	ap.logSink = w
+	log.SetOutput(w)
+	log.Config.ForceColor = colormode
+	log.SetColorMode()
+}
+
+// LogSink returns the writer set by SetLogSink (nil if never set).
+func (ap *AnsiPixels) LogSink() io.Writer {
+	return ap.logSink
+}
+
+// LogBuffer is an io.Writer keeping the last lines written to it (bounded scrollback), for instance
+// to show recent log lines in a dedicated region of the screen instead of over the drawing:
+//
+//	lb := ansipixels.NewLogBuffer(100, nil)
+//	ap.SetLogSink(lb)
+//	...
+//	for i, l := range lb.Tail(5) {
+//		ap.WriteAtStr(0, ap.H-5+i, l)
+//	}
+type LogBuffer struct {
+	// Optional writer to also send the output to (e.g. a file).
+	Out     io.Writer
+	mu      sync.Mutex
+	lines   []string
+	next    int // next index to write in lines (ring buffer).
+	full    bool
+	partial []byte // incomplete last line.
+}
+
+// NewLogBuffer creates a LogBuffer keeping up to capacity lines and also writing
+// to out if it isn't nil.
+func NewLogBuffer(capacity int, out io.Writer) *LogBuffer {
+	return &LogBuffer{Out: out, lines: make([]string, capacity)}
+}
+
+func (lb *LogBuffer) Write(buf []byte) (int, error) {
+	lb.mu.Lock()
+	lb.partial = append(lb.partial, buf...)
+	for {
+		idx := bytes.IndexByte(lb.partial, '\n')
+		if idx == -1 {
+			break
+		}
+		lb.add(string(bytes.TrimSuffix(lb.partial[:idx], []byte{'\r'})))
+		lb.partial = lb.partial[idx+1:]
+	}
+	lb.mu.Unlock()
+	if lb.Out != nil {
+		return lb.Out.Write(buf)
+	}
+	return len(buf), nil
+}
+
+func (lb *LogBuffer) add(line string) {
+	if len(lb.lines) == 0 {
+		return
+	}
+	lb.lines[lb.next] = line
+	lb.next++
+	if lb.next == len(lb.lines) {
+		lb.next = 0
+		lb.full = true
+	}
+}
+
+// Tail returns (a copy of) the last n complete lines, oldest first.
+func (lb *LogBuffer) Tail(n int) []string {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	count := lb.next
+	if lb.full {
+		count = len(lb.lines)
+	}
+	n = min(max(n, 0), count)
+	res := make([]string, 0, n)
+	for i := lb.next - n; i < lb.next; i++ {
+		res = append(res, lb.lines[(i+len(lb.lines))%len(lb.lines)])
+	}
+	return res
+}
@@ -0,0 +1,25 @@
+package ansipixels
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestLogBufferTail(t *testing.T) {
+	lb := NewLogBuffer(3, nil)
+	if tail := lb.Tail(5); len(tail) != 0 {
+		t.Errorf("expected empty tail, got %q", tail)
+	}
+	fmt.Fprintf(lb, "line 1\r\nline 2\npart")
+	if tail := lb.Tail(5); !slices.Equal(tail, []string{"line 1", "line 2"}) {
+		t.Errorf("unexpected tail %q", tail)
+	}
+	fmt.Fprintf(lb, "ial 3\nline 4\nline 5\n")
+	if tail := lb.Tail(5); !slices.Equal(tail, []string{"partial 3", "line 4", "line 5"}) {
+		t.Errorf("unexpected tail after wrap around %q", tail)
+	}
+	if tail := lb.Tail(1); !slices.Equal(tail, []string{"line 5"}) {
+		t.Errorf("unexpected tail(1) %q", tail)
+	}
+}
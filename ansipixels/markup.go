@@ -0,0 +1,102 @@
+package ansipixels
+
+import "strings"
+
+// Names usable in [Markup] tags.
+var markupCodes = map[string]string{
+	"bold":          Bold,
+	"dim":           Dim,
+	"italic":        Italic,
+	"underline":     Underlined,
+	"blink":         Blink,
+	"reverse":       Reverse,
+	"strike":        Strikethrough,
+	"strikethrough": Strikethrough,
+	"black":         Black,
+	"red":           Red,
+	"green":         Green,
+	"yellow":        Yellow,
+	"blue":          Blue,
+	"purple":        Purple,
+	"cyan":          Cyan,
+	"gray":          Gray,
+	"darkgray":      DarkGray,
+	"brightred":     BrightRed,
+	"brightgreen":   BrightGreen,
+	"brightyellow":  BrightYellow,
+	"brightblue":    BrightBlue,
+	"brightpurple":  BrightPurple,
+	"brightcyan":    BrightCyan,
+	"white":         White,
+	"orange":        Orange,
+}
+
+// Markup translates bracket tags into ansi codes: "[red]error[/] and [bold italic]emphasis[/]".
+// Tags contain one or more (space separated) color or attribute names (e.g. bold, dim, italic,
+// underline, blink, reverse, strike, red, brightred, orange, ...) and "[/]" ends the last opened
+// tag, restoring the enclosing ones (see [StyleStack]). "[[" is a literal "[" and brackets
+// not forming a valid tag are kept as is. Unclosed tags are reset at the end.
+func Markup(s string) string {
+	var sb strings.Builder
+	var ss StyleStack
+	for {
+		i := strings.IndexByte(s, '[')
+		if i == -1 {
+			sb.WriteString(s)
+			break
+		}
+		sb.WriteString(s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "[[") {
+			sb.WriteByte('[')
+			s = s[2:]
+			continue
+		}
+		end := strings.IndexByte(s, ']')
+		if end == -1 {
+			sb.WriteString(s)
+			break
+		}
+		tag := s[1:end]
+		if tag == "/" {
+			sb.WriteString(ss.Pop())
+			s = s[end+1:]
+			continue
+		}
+		codes, ok := markupTag(tag)
+		if !ok {
+			sb.WriteByte('[') // not a tag, keep as is.
+			s = s[1:]
+			continue
+		}
+		sb.WriteString(ss.Push(NewStyle(codes...)))
+		s = s[end+1:]
+	}
+	if ss.Depth() > 0 {
+		sb.WriteString(Reset)
+	}
+	return sb.String()
+}
+
+func markupTag(tag string) ([]string, bool) {
+	names := strings.Fields(tag)
+	if len(names) == 0 {
+		return nil, false
+	}
+	codes := make([]string, 0, len(names))
+	for _, n := range names {
+		c, ok := markupCodes[strings.ToLower(n)]
+		if !ok {
+			return nil, false
+		}
+		codes = append(codes, c)
+	}
+	return codes, true
+}
+
+// WriteMarkup writes the [Markup] translated msg at x, y and returns its (visible) width.
+func (ap *AnsiPixels) WriteMarkup(x, y int, msg string) int {
+	s := Markup(msg)
+	ap.WriteAtStr(x, y, s)
+	return ap.ScreenWidth(s)
+}
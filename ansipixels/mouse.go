@@ -1,16 +1,37 @@
 package ansipixels
 
-import "bytes"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"fortio.org/log"
+)
+
+// MouseMode is a set of enabled mouse reporting modes (see the On/Off functions below).
+type MouseMode uint8
+
+const (
+	NoMouse           MouseMode = 0
+	MouseClickMode    MouseMode = 1 << (iota - 1) // MouseClickOn
+	MouseTrackingMode                             // MouseTrackingOn
+	MouseX10Mode                                  // MouseX10On
+	MousePixelsMode                               // MousePixelsOn
+)
 
 func (ap *AnsiPixels) MouseClickOn() {
 	// https://github.com/ghostty-org/ghostty/blame/main/website/app/vt/xtshiftescape/page.mdx
 	// Let us see shift key modifiers:
 	ap.WriteString("\033[>1s")
 	ap.WriteString("\033[?1000h")
+	ap.mouseMode |= MouseClickMode
 }
 
 func (ap *AnsiPixels) MouseClickOff() {
 	ap.WriteString("\033[?1000l")
+	ap.mouseMode &^= MouseClickMode
 }
 
 func (ap *AnsiPixels) MouseTrackingOn() {
@@ -18,26 +39,149 @@ func (ap *AnsiPixels) MouseTrackingOn() {
 	// Let us see shift key modifiers:
 	ap.WriteString("\033[>1s")
 	ap.WriteString("\033[?1003h")
+	ap.mouseMode |= MouseTrackingMode
 }
 
 func (ap *AnsiPixels) MouseTrackingOff() {
 	ap.WriteString("\033[?1003l")
+	ap.mouseMode &^= MouseTrackingMode
 }
 
 func (ap *AnsiPixels) MouseX10Off() {
 	ap.WriteString("\033[?9l")
+	ap.mouseMode &^= MouseX10Mode
 }
 
 func (ap *AnsiPixels) MouseX10On() {
 	ap.WriteString("\033[?9h")
+	ap.mouseMode |= MouseX10Mode
 }
 
 func (ap *AnsiPixels) MousePixelsOn() {
 	ap.WriteString("\x1b[?1016h")
+	ap.mouseMode |= MousePixelsMode
 }
 
 func (ap *AnsiPixels) MousePixelsOff() {
 	ap.WriteString("\x1b[?1016l")
+	ap.mouseMode &^= MousePixelsMode
+}
+
+// CurrentMouseMode returns the set of mouse modes currently enabled through this AnsiPixels.
+func (ap *AnsiPixels) CurrentMouseMode() MouseMode {
+	return ap.mouseMode
+}
+
+// SetMouseMode enables exactly the given set of modes, turning off the other ones.
+func (ap *AnsiPixels) SetMouseMode(mode MouseMode) {
+	for _, m := range []struct {
+		mode    MouseMode
+		on, off func()
+	}{
+		{MouseClickMode, ap.MouseClickOn, ap.MouseClickOff},
+		{MouseTrackingMode, ap.MouseTrackingOn, ap.MouseTrackingOff},
+		{MouseX10Mode, ap.MouseX10On, ap.MouseX10Off},
+		{MousePixelsMode, ap.MousePixelsOn, ap.MousePixelsOff},
+	} {
+		wanted := mode&m.mode != 0
+		current := ap.mouseMode&m.mode != 0
+		switch {
+		case wanted && !current:
+			m.on()
+		case !wanted && current:
+			m.off()
+		}
+	}
+}
+
+// PushMouseMode saves the current mouse modes and switches to the given ones (e.g. NoMouse
+// for a modal dialog). Use PopMouseMode to restore exactly the previous state.
+func (ap *AnsiPixels) PushMouseMode(mode MouseMode) {
+	ap.mouseStack = append(ap.mouseStack, ap.mouseMode)
+	ap.SetMouseMode(mode)
+}
+
+// PopMouseMode restores the mouse modes saved by the matching PushMouseMode.
+func (ap *AnsiPixels) PopMouseMode() {
+	l := len(ap.mouseStack)
+	if l == 0 {
+		log.Errf("PopMouseMode called without matching PushMouseMode")
+		return
+	}
+	ap.SetMouseMode(ap.mouseStack[l-1])
+	ap.mouseStack = ap.mouseStack[:l-1]
+}
+
+var cellSizeRegexp = regexp.MustCompile(`\033\[6;(\d+);(\d+)t`)
+
+// ReadCellSize queries the terminal (using \033[16t) for the size in pixels of a character cell.
+// A cursor position request is sent right after so terminals not supporting it return an error
+// instead of blocking, as do terminals not answering in time. The result (or the error) is cached
+// for PixelToCell and CellToPixel, so it should be called once during setup, after Open, and again
+// if the font size changes. Like ReadCursorPos, this also synchronizes the display and ends the
+// syncmode.
+func (ap *AnsiPixels) ReadCellSize() (int, int, error) {
+	ap.cellW, ap.cellH = 0, 0
+	res, err := ap.queryWithTimeout("\033[16t\033[6n", cursPosRegexp, "cell size")
+	if err == nil && res == nil {
+		err = errors.New("no cell size response")
+	}
+	if err == nil {
+		err = ap.parseCellSize()
+	}
+	ap.cellErr = err
+	return ap.cellW, ap.cellH, err
+}
+
+// Sets the cell size from the response found in ap.Data, which is removed from it.
+func (ap *AnsiPixels) parseCellSize() error {
+	ap.cellW, ap.cellH = 0, 0
+	loc := cellSizeRegexp.FindSubmatchIndex(ap.Data)
+	if loc == nil {
+		return errors.New("no cell size response (\\033[16t not supported)")
+	}
+	h, _ := strconv.Atoi(string(ap.Data[loc[2]:loc[3]])) // can't fail given the regexp.
+	w, _ := strconv.Atoi(string(ap.Data[loc[4]:loc[5]]))
+	ap.Data = append(ap.Data[:loc[0]], ap.Data[loc[1]:]...)
+	if w <= 0 || h <= 0 {
+		return fmt.Errorf("invalid cell size %dx%d", w, h)
+	}
+	ap.cellW, ap.cellH = w, h
+	return nil
+}
+
+// CellPixelSize returns the size in pixels of a character cell obtained by [ReadCellSize] (or
+// [QueryCapabilities]), or why it isn't known. It never queries the terminal, so it's safe to use
+// while drawing. Useful for pixel precise mouse or image work: cells are not always exactly twice
+// as tall as wide.
+func (ap *AnsiPixels) CellPixelSize() (w, h int, err error) {
+	switch {
+	case ap.cellW > 0:
+		return ap.cellW, ap.cellH, nil
+	case ap.cellErr != nil:
+		return 0, 0, ap.cellErr
+	default:
+		return 0, 0, errors.New("cell size not queried (see ReadCellSize)")
+	}
+}
+
+// PixelToCell converts 1 based pixel coordinates (as reported in MousePixelsOn mode) to 1 based
+// cell coordinates (like Mx, My in normal mode), using the cell size from [ReadCellSize]. ok is
+// false when the cell size isn't known.
+func (ap *AnsiPixels) PixelToCell(px, py int) (cx, cy int, ok bool) {
+	if ap.cellW <= 0 {
+		return 0, 0, false
+	}
+	return (px-1)/ap.cellW + 1, (py-1)/ap.cellH + 1, true
+}
+
+// CellToPixel converts 1 based cell coordinates to the 1 based pixel coordinates of the top left
+// of that cell, using the cell size from [ReadCellSize]. ok is false when the cell size isn't known.
+func (ap *AnsiPixels) CellToPixel(cx, cy int) (px, py int, ok bool) {
+	if ap.cellW <= 0 {
+		return 0, 0, false
+	}
+	return (cx-1)*ap.cellW + 1, (cy-1)*ap.cellH + 1, true
 }
 
 var mouseDataPrefix = []byte{0x1b, '[', 'M'}
@@ -68,6 +212,7 @@ const (
 	MouseMiddle     = 0b01
 	MouseRight      = 0b10
 	MouseMove       = 0b100000
+	MouseRelease    = 0b11 // button released (in non SGR modes, we don't know which one)
 	MouseWheelUp    = 0b1000000
 	MouseWheelDown  = 0b1000001
 	Shift           = 0b000100
@@ -93,11 +238,11 @@ func (ap *AnsiPixels) AltMod() bool {
 }
 
 func (ap *AnsiPixels) ShiftMod() bool {
-	return ap.Mbuttons&Alt != 0
+	return ap.Mbuttons&Shift != 0
 }
 
 func (ap *AnsiPixels) CtrlMod() bool {
-	return ap.Mbuttons&Alt != 0
+	return ap.Mbuttons&Ctrl != 0
 }
 
 func (ap *AnsiPixels) AnyModifier() bool {
@@ -108,6 +253,24 @@ func (ap *AnsiPixels) LeftClick() bool {
 	return ap.Mouse && ((ap.Mbuttons & AnyModifierMask) == MouseLeft)
 }
 
+// ModifiedClick returns true if the mouse event is a click of the given button (MouseLeft, MouseMiddle
+// or MouseRight) with exactly the given modifiers (e.g. Ctrl or Shift|Alt) held down.
+func (ap *AnsiPixels) ModifiedClick(button, modifiers int) bool {
+	return ap.Mouse && ap.Mbuttons == button|modifiers
+}
+
+func (ap *AnsiPixels) CtrlLeftClick() bool {
+	return ap.ModifiedClick(MouseLeft, Ctrl)
+}
+
+func (ap *AnsiPixels) ShiftLeftClick() bool {
+	return ap.ModifiedClick(MouseLeft, Shift)
+}
+
+func (ap *AnsiPixels) AltLeftClick() bool {
+	return ap.ModifiedClick(MouseLeft, Alt)
+}
+
 func (ap *AnsiPixels) Middle() bool {
 	return ap.Mouse && ((ap.Mbuttons & AnyModifierMask) == MouseMiddle)
 }
@@ -127,3 +290,73 @@ func (ap *AnsiPixels) MiddleDrag() bool {
 func (ap *AnsiPixels) RightDrag() bool {
 	return ap.Mouse && ((ap.Mbuttons & AnyModifierMask) == MouseMove|MouseRight)
 }
+
+type dragState struct {
+	active         bool
+	button         int
+	startX, startY int
+	lastX, lastY   int
+}
+
+// BeginDrag starts a drag session from the current mouse button down event (e.g. when LeftClick()
+// is true). Motion tracking is enabled for the duration of the session and the previous mouse
+// modes are restored when it ends. Returns false if the current event isn't a button press.
+// Calling it while a session is already in progress is a no-op (and returns true).
+func (ap *AnsiPixels) BeginDrag() bool {
+	if ap.drag.active {
+		return true
+	}
+	button := ap.Mbuttons & MouseRelease
+	if !ap.Mouse || ap.Mbuttons&(MouseMove|MouseWheelUp) != 0 || button == MouseRelease {
+		return false
+	}
+	ap.drag = dragState{
+		active: true,
+		button: button,
+		startX: ap.Mx,
+		startY: ap.My,
+		lastX:  ap.Mx,
+		lastY:  ap.My,
+	}
+	ap.PushMouseMode(ap.mouseMode | MouseTrackingMode)
+	return true
+}
+
+// Dragging returns true while a drag session started by BeginDrag is in progress.
+func (ap *AnsiPixels) Dragging() bool {
+	return ap.drag.active
+}
+
+// DragStart returns the position where the current (or last) drag session started.
+func (ap *AnsiPixels) DragStart() (int, int) {
+	return ap.drag.startX, ap.drag.startY
+}
+
+// DragDelta is to be called after each read while Dragging(). It returns the movement since the
+// previous drag event and whether the drag is still ongoing: once the button is released (or any
+// other mouse event is received) the session ends and the previous mouse modes are restored.
+// Non mouse input (keys) returns 0, 0, true.
+func (ap *AnsiPixels) DragDelta() (int, int, bool) {
+	if !ap.drag.active {
+		return 0, 0, false
+	}
+	if !ap.Mouse {
+		return 0, 0, true
+	}
+	dx, dy := ap.Mx-ap.drag.lastX, ap.My-ap.drag.lastY
+	ap.drag.lastX, ap.drag.lastY = ap.Mx, ap.My
+	if ap.Mbuttons&AnyModifierMask != MouseMove|ap.drag.button {
+		ap.EndDrag()
+		return dx, dy, false
+	}
+	return dx, dy, true
+}
+
+// EndDrag ends the current drag session, if any, restoring the mouse modes.
+func (ap *AnsiPixels) EndDrag() {
+	if !ap.drag.active {
+		return
+	}
+	ap.drag.active = false
+	ap.PopMouseMode()
+}
@@ -0,0 +1,59 @@
+package ansipixels
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"fortio.org/terminal"
+)
+
+func TestReadCellSize(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	var out strings.Builder
+	ap := &AnsiPixels{
+		Out:           bufio.NewWriter(&out),
+		InWithTimeout: terminal.NewTimeoutReader(r, time.Second),
+		FPS:           60,
+	}
+	if _, _, ok := ap.PixelToCell(10, 10); ok {
+		t.Errorf("expected no conversion before ReadCellSize")
+	}
+	_, _ = w.WriteString("\033[6;20;10tk\033[5;1R")
+	cw, ch, err := ap.ReadCellSize()
+	if err != nil || cw != 10 || ch != 20 {
+		t.Fatalf("unexpected cell size %dx%d, %v", cw, ch, err)
+	}
+	if string(ap.Data) != "k" {
+		t.Errorf("expected the key to be left in Data, got %q", ap.Data)
+	}
+	out.Reset()
+	if x, y, ok := ap.PixelToCell(25, 41); !ok || x != 3 || y != 3 {
+		t.Errorf("unexpected PixelToCell %d, %d, %t", x, y, ok)
+	}
+	if x, y, ok := ap.CellToPixel(3, 3); !ok || x != 21 || y != 41 {
+		t.Errorf("unexpected CellToPixel %d, %d, %t", x, y, ok)
+	}
+	if out.Len() != 0 {
+		t.Errorf("conversions shouldn't query the terminal, got %q", out.String())
+	}
+	// Terminal not supporting the cell size query: only the cursor position is answered.
+	_, _ = w.WriteString("\033[5;1R")
+	if _, _, err = ap.ReadCellSize(); err == nil {
+		t.Errorf("expected an error without cell size response")
+	}
+	if _, _, ok := ap.PixelToCell(25, 41); ok {
+		t.Errorf("expected no conversion after a failed ReadCellSize")
+	}
+	out.Reset()
+	if _, _, err = ap.CellPixelSize(); err == nil || out.Len() != 0 {
+		t.Errorf("expected the cached error, without query, got %v and %q", err, out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package ansipixels
+
+import (
+	"image/color"
+	"math"
+)
+
+// ColorDistance returns the perceptual distance between a and b: the euclidean distance in the
+// OKLab color space (0 for the same color, about 1 between black and white).
+func ColorDistance(a, b color.RGBA) float64 {
+	l1, a1, b1 := rgbToOKLab(a)
+	l2, a2, b2 := rgbToOKLab(b)
+	return math.Sqrt((l1-l2)*(l1-l2) + (a1-a2)*(a1-a2) + (b1-b2)*(b1-b2))
+}
+
+// NearestBasic returns the index (0-15, i.e. 30+idx or 90+idx-8 codes) of the [ANSI16Palette]
+// color perceptually closest to c, for minimal escape sequences output.
+func NearestBasic(c color.RGBA) int {
+	return nearest(c, 0, 16, func(i int) color.RGBA { return ANSI16Palette[i] })
+}
+
+// Nearest256 returns the index (16-255, for \033[38;5;idxm) of the xterm 256 colors cube or grays
+// ramp entry perceptually closest to c. The first 16, which depend on the terminal theme, are not
+// considered (see [NearestBasic] for those).
+func Nearest256(c color.RGBA) uint8 {
+	return uint8(nearest(c, 16, 256, Color256)) //nolint:gosec // 16-255 by construction.
+}
+
+// Color256 returns the (xterm default) RGB value of the 256 colors entry idx.
+func Color256(idx int) color.RGBA {
+	switch {
+	case idx < 16:
+		return ANSI16Palette[idx]
+	case idx < 232:
+		idx -= 16
+		return color.RGBA{R: cubeLevel(idx / 36), G: cubeLevel(idx / 6 % 6), B: cubeLevel(idx % 6), A: 255}
+	default:
+		v := uint8(8 + 10*(idx-232)) //nolint:gosec // 8-238.
+		return color.RGBA{R: v, G: v, B: v, A: 255}
+	}
+}
+
+// Returns the value of level (0-5) of the 6x6x6 color cube.
+func cubeLevel(level int) uint8 {
+	if level == 0 {
+		return 0
+	}
+	return uint8(55 + 40*level) //nolint:gosec // 95-255.
+}
+
+// Returns the index, in [from, to), of the closest color to c.
+func nearest(c color.RGBA, from, to int, colorAt func(i int) color.RGBA) int {
+	best, bestD := from, math.Inf(1)
+	for i := from; i < to; i++ {
+		if d := ColorDistance(c, colorAt(i)); d < bestD {
+			best, bestD = i, d
+		}
+	}
+	return best
+}
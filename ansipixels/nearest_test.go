@@ -0,0 +1,36 @@
+package ansipixels
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestNearestColors(t *testing.T) {
+	tests := []struct {
+		c        color.RGBA
+		basic    int
+		color256 uint8
+	}{
+		{color.RGBA{255, 0, 0, 255}, 9, 196},      // pure red -> bright red.
+		{color.RGBA{200, 10, 10, 255}, 1, 160},    // darker red -> red.
+		{color.RGBA{0, 0, 0, 255}, 0, 16},         // black.
+		{color.RGBA{255, 255, 255, 255}, 15, 231}, // white.
+		{color.RGBA{128, 128, 128, 255}, 8, 244},  // mid gray -> dark gray and gray ramp.
+		{color.RGBA{0, 200, 0, 255}, 2, 40},       // green.
+		{color.RGBA{250, 250, 10, 255}, 11, 226},  // yellow.
+	}
+	for _, tc := range tests {
+		if got := NearestBasic(tc.c); got != tc.basic {
+			t.Errorf("NearestBasic(%v) = %d, expected %d", tc.c, got, tc.basic)
+		}
+		if got := Nearest256(tc.c); got != tc.color256 {
+			t.Errorf("Nearest256(%v) = %d (%v), expected %d", tc.c, got, Color256(int(got)), tc.color256)
+		}
+	}
+	if d := ColorDistance(color.RGBA{1, 2, 3, 255}, color.RGBA{1, 2, 3, 255}); d != 0 {
+		t.Errorf("distance to self %f", d)
+	}
+	if Color256(196) != (color.RGBA{255, 0, 0, 255}) || Color256(232) != (color.RGBA{8, 8, 8, 255}) {
+		t.Errorf("unexpected 256 colors values %v %v", Color256(196), Color256(232))
+	}
+}
@@ -0,0 +1,128 @@
+package ansipixels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Panel is a rectangular sub-region of the screen with its own origin: coordinates passed
+// to its methods are relative to the panel's top left corner (0,0) and output is clipped
+// to the panel (and the screen), so drawing through a panel never spills outside of it.
+type Panel struct {
+	AP         *AnsiPixels
+	X, Y, W, H int // Position and size on the screen (0 based, like MoveCursor).
+}
+
+// NewPanel returns a panel at x, y (screen coordinates) of size w x h.
+func (ap *AnsiPixels) NewPanel(x, y, w, h int) *Panel {
+	return &Panel{AP: ap, X: x, Y: y, W: w, H: h}
+}
+
+// ScreenPanel returns a panel covering the whole screen (without the Margin).
+func (ap *AnsiPixels) ScreenPanel() *Panel {
+	return ap.NewPanel(ap.Margin, ap.Margin, ap.W-2*ap.Margin, ap.H-2*ap.Margin)
+}
+
+// Sub returns a child panel at x, y relative to p, clipped to p.
+func (p *Panel) Sub(x, y, w, h int) *Panel {
+	x0, y0 := max(x, 0), max(y, 0)
+	w = max(0, min(x+w, p.W)-x0)
+	h = max(0, min(y+h, p.H)-y0)
+	return p.AP.NewPanel(p.X+x0, p.Y+y0, w, h)
+}
+
+// Inner returns the panel inside a 1 character border (e.g. after DrawRoundBox).
+func (p *Panel) Inner() *Panel {
+	return p.Sub(1, 1, p.W-2, p.H-2)
+}
+
+// Contains returns true if the screen coordinates x, y are inside the panel.
+func (p *Panel) Contains(x, y int) bool {
+	return x >= p.X && x < p.X+p.W && y >= p.Y && y < p.Y+p.H
+}
+
+// MoveCursor moves the cursor to x, y relative to the panel.
+func (p *Panel) MoveCursor(x, y int) {
+	p.AP.MoveCursor(p.X+x, p.Y+y)
+}
+
+// WriteAtStr writes msg at x, y relative to the panel, clipped to the panel (and screen) width.
+// msg should be a single line; ansi sequences are passed through.
+func (p *Panel) WriteAtStr(x, y int, msg string) {
+	if y < 0 || y >= p.H || p.Y+y < 0 || p.Y+y >= p.AP.H {
+		return
+	}
+	// Columns available on the right and to skip on the left.
+	right := min(p.W, p.AP.W-p.X)
+	left := max(0, -x, -p.X-x)
+	if x+left >= right {
+		return
+	}
+	msg = clipColumns(msg, left, right-x-left)
+	p.MoveCursor(x+left, y)
+	p.AP.WriteString(msg)
+}
+
+// WriteAt is like WriteAtStr with fmt.Sprintf formatting.
+func (p *Panel) WriteAt(x, y int, msg string, args ...interface{}) {
+	p.WriteAtStr(x, y, fmt.Sprintf(msg, args...))
+}
+
+// WriteCentered writes msg centered horizontally on line y of the panel.
+func (p *Panel) WriteCentered(y int, msg string, args ...interface{}) {
+	s := fmt.Sprintf(msg, args...)
+	p.WriteAtStr((p.W-p.AP.ScreenWidth(s))/2, y, s)
+}
+
+// Clear erases the content of the panel (with the current background color).
+func (p *Panel) Clear() {
+	p.AP.EraseRect(p.X, p.Y, p.W, p.H)
+}
+
+// DrawRoundBox draws a round corners box around the edge of the panel.
+func (p *Panel) DrawRoundBox() {
+	p.AP.DrawRoundBox(p.X, p.Y, p.W, p.H)
+}
+
+// DrawSquareBox draws a square corners box around the edge of the panel.
+func (p *Panel) DrawSquareBox() {
+	p.AP.DrawSquareBox(p.X, p.Y, p.W, p.H)
+}
+
+// Returns msg minus the first skip screen columns and truncated to maxWidth columns.
+// Ansi sequences are kept. A wide character straddling the left edge is replaced by a space.
+func clipColumns(msg string, skip, maxWidth int) string {
+	var sb strings.Builder
+	w := 0
+	state := -1
+	for len(msg) > 0 {
+		if msg[0] == 27 {
+			if loc := cleanAnsiRE.FindStringIndex(msg); loc != nil && loc[0] == 0 {
+				sb.WriteString(msg[:loc[1]])
+				msg = msg[loc[1]:]
+				state = -1 // the state is for the (now skipped) next character.
+				continue
+			}
+		}
+		var g string
+		var gw int
+		g, msg, gw, state = uniseg.FirstGraphemeClusterInString(msg, state)
+		if skip > 0 {
+			skip -= gw
+			if skip < 0 && w-skip <= maxWidth {
+				w -= skip
+				sb.WriteString(strings.Repeat(" ", -skip))
+			}
+			continue
+		}
+		if w+gw > maxWidth {
+			maxWidth = w // drop the rest but keep going for the ansi sequences (e.g. a final Reset).
+			continue
+		}
+		w += gw
+		sb.WriteString(g)
+	}
+	return sb.String()
+}
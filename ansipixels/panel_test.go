@@ -0,0 +1,76 @@
+package ansipixels
+
+import (
+	"bufio"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestClipColumns(t *testing.T) {
+	tests := []struct {
+		msg      string
+		skip     int
+		maxWidth int
+		expected string
+	}{
+		{"abcdef", 0, 10, "abcdef"},
+		{"abcdef", 2, 10, "cdef"},
+		{"abcdef", 1, 3, "bcd"},
+		{"a" + Red + "bc" + Reset, 0, 2, "a" + Red + "b" + Reset},
+		{"ab" + Red + "cd", 3, 5, Red + "d"},
+		{"日本語", 1, 10, " 本語"},
+		{"日本語", 0, 3, "日"},
+	}
+	for _, tt := range tests {
+		if got := clipColumns(tt.msg, tt.skip, tt.maxWidth); got != tt.expected {
+			t.Errorf("clipColumns(%q, %d, %d) = %q, expected %q", tt.msg, tt.skip, tt.maxWidth, got, tt.expected)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	ap := &AnsiPixels{W: 80, H: 24}
+	header, rest := ap.ScreenPanel().SplitTop(1)
+	if header.H != 1 || rest.Y != 1 || rest.H != 23 || rest.W != 80 {
+		t.Errorf("unexpected SplitTop %+v %+v", header, rest)
+	}
+	left, right := rest.SplitH(0.25)
+	if left.W != 20 || right.X != 20 || right.W != 60 || right.Y != 1 {
+		t.Errorf("unexpected SplitH %+v %+v", left, right)
+	}
+	cells := right.Grid(3, 2)
+	if len(cells) != 6 {
+		t.Fatalf("expected 6 grid cells, got %d", len(cells))
+	}
+	last := cells[5]
+	if last.X+last.W != 80 || last.Y+last.H != 24 || cells[0].X != 20 || cells[0].Y != 1 {
+		t.Errorf("grid doesn't cover the panel: %+v %+v", cells[0], last)
+	}
+	c := ap.CenteredPanel(0.5, 0.1, 10, 4)
+	if c.W != 40 || c.H != 4 || c.X != 20 || c.Y != 10 {
+		t.Errorf("unexpected CenteredPanel %+v", c)
+	}
+	if x, y := ap.Pct(1, 0.5); x != 79 || y != 12 {
+		t.Errorf("unexpected Pct %d, %d", x, y)
+	}
+}
+
+func TestDrawPanelShadow(t *testing.T) {
+	var out strings.Builder
+	// No In: querying the terminal background while drawing would panic.
+	ap := &AnsiPixels{W: 20, H: 10, Out: bufio.NewWriter(&out), TrueColor: true}
+	bg := color.RGBA{R: 200, G: 100, B: 50, A: 255}
+	ap.DrawPanel(1, 1, 6, 4, bg, true)
+	_ = ap.Out.Flush()
+	if !strings.Contains(out.String(), "\033[48;2;50;25;13m") {
+		t.Errorf("expected the shadow blended with black, got %q", out.String())
+	}
+	out.Reset()
+	ap.background = &color.RGBA{R: 255, G: 255, B: 255, A: 255} // as set by QueryBackgroundColor.
+	ap.DrawPanel(1, 1, 6, 4, bg, true)
+	_ = ap.Out.Flush()
+	if !strings.Contains(out.String(), "\033[48;2;178;153;140m") {
+		t.Errorf("expected the shadow blended with the queried background, got %q", out.String())
+	}
+}
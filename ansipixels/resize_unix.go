@@ -8,8 +8,14 @@ import (
 	"syscall"
 )
 
-var signalList = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGWINCH}
+var signalList = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGWINCH, syscall.SIGCONT}
 
+// IsResizeSignal returns true for signals requiring a full redraw: resize and continue
+// (after a job control stop, the screen may have been changed by something else).
 func (ap *AnsiPixels) IsResizeSignal(s os.Signal) bool {
-	return s == syscall.SIGWINCH
+	return s == syscall.SIGWINCH || s == syscall.SIGCONT
+}
+
+func isContinueSignal(s os.Signal) bool {
+	return s == syscall.SIGCONT
 }
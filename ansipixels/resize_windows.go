@@ -11,3 +11,5 @@ import (
 var signalList = []os.Signal{os.Interrupt, syscall.SIGTERM}
 
 func (ap *AnsiPixels) IsResizeSignal(s os.Signal) bool { return false }
+
+func isContinueSignal(_ os.Signal) bool { return false }
@@ -0,0 +1,47 @@
+package ansipixels
+
+import "strconv"
+
+// SetScrollRegion restricts scrolling ([ScrollUp], [ScrollDown] and newlines on the bottom line of
+// the region) to the lines from top to bottom (0 based, inclusive, clamped to the screen), for
+// instance for a log pane scrolling independently of the rest of the screen. As per DECSTBM, the
+// cursor moves to the top left corner. An empty region (bottom <= top) resets it, see
+// [ResetScrollRegion]. Restore resets the region if one is set.
+func (ap *AnsiPixels) SetScrollRegion(top, bottom int) {
+	top, bottom = max(top, 0), min(bottom, ap.H-1)
+	if bottom <= top {
+		ap.ResetScrollRegion()
+		return
+	}
+	ap.WriteString("\033[" + strconv.Itoa(top+1) + ";" + strconv.Itoa(bottom+1) + "r")
+	ap.x, ap.y = 0, 0
+	ap.scrollRegion = true
+}
+
+// ResetScrollRegion makes the whole screen scroll again (and moves the cursor to the top left
+// corner).
+func (ap *AnsiPixels) ResetScrollRegion() {
+	ap.WriteString("\033[r")
+	ap.x, ap.y = 0, 0
+	ap.scrollRegion = false
+}
+
+// ScrollUp scrolls the content of the scroll region (whole screen by default, see
+// [SetScrollRegion]) up by n lines, the new lines at the bottom are blank. The cursor doesn't move.
+func (ap *AnsiPixels) ScrollUp(n int) {
+	ap.scroll(n, 'S')
+}
+
+// ScrollDown scrolls the content of the scroll region down by n lines, the new lines at the top
+// are blank. The cursor doesn't move.
+func (ap *AnsiPixels) ScrollDown(n int) {
+	ap.scroll(n, 'T')
+}
+
+func (ap *AnsiPixels) scroll(n int, final byte) {
+	n = min(n, ap.H) // more is the same as clearing the region.
+	if n <= 0 {
+		return
+	}
+	ap.WriteString("\033[" + strconv.Itoa(n) + string(final))
+}
@@ -0,0 +1,59 @@
+package ansipixels
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestScrollRegion(t *testing.T) {
+	var out strings.Builder
+	ap := &AnsiPixels{W: 10, H: 5, Out: bufio.NewWriter(&out)}
+	check := func(expected string) {
+		t.Helper()
+		_ = ap.Out.Flush()
+		if got := out.String(); got != expected {
+			t.Errorf("got %q, expected %q", got, expected)
+		}
+		out.Reset()
+	}
+	ap.SetScrollRegion(1, 3)
+	check("\033[2;4r")
+	ap.SetScrollRegion(-2, 42) // clamped.
+	check("\033[1;5r")
+	ap.ScrollUp(2)
+	ap.ScrollDown(1)
+	ap.ScrollUp(0)
+	ap.ScrollDown(99) // clamped.
+	check("\033[2S\033[1T\033[5T")
+	ap.SetScrollRegion(3, 3) // empty region: reset.
+	check("\033[r")
+	if ap.scrollRegion {
+		t.Errorf("scroll region should be reset")
+	}
+}
+
+func TestScreenBufferScroll(t *testing.T) {
+	sb := newScreenBuffer(6, 5)
+	ap := &AnsiPixels{W: 6, H: 5, Out: bufio.NewWriter(sb), snapshot: sb}
+	for i := range ap.H {
+		ap.WriteAtStr(0, i, "line"+string(rune('0'+i)))
+	}
+	ap.SetScrollRegion(1, 3)
+	ap.ScrollUp(1)
+	if got, expected := ap.Snapshot(), "line0\nline2\nline3\n\nline4\n"; got != expected {
+		t.Errorf("after scroll up got %q, expected %q", got, expected)
+	}
+	ap.ScrollDown(2)
+	if got, expected := ap.Snapshot(), "line0\n\n\nline2\nline4\n"; got != expected {
+		t.Errorf("after scroll down got %q, expected %q", got, expected)
+	}
+	ap.MoveCursor(0, 3)
+	ap.WriteString("a\r\nb")
+	ap.ResetScrollRegion()
+	ap.MoveCursor(0, 4)
+	ap.WriteString("\r\nc") // no scrolling without an explicit region.
+	if got, expected := ap.Snapshot(), "line0\n\naine2\nb\nline4\n"; got != expected {
+		t.Errorf("after newlines got %q, expected %q", got, expected)
+	}
+}
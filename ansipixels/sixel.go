@@ -0,0 +1,137 @@
+package ansipixels
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"os"
+	"strings"
+
+	"fortio.org/log"
+	"golang.org/x/image/draw"
+)
+
+// Cell size assumed for Sixel and kitty graphics images when it can't be queried.
+const defaultCellW, defaultCellH = 8, 16
+
+// DetectSixel returns whether the terminal is known to support Sixel graphics, from its TERM
+// (e.g. to set [Sixel] by default, as there is no reliable and non blocking way to ask).
+func DetectSixel() bool {
+	tenv := os.Getenv("TERM")
+	if strings.Contains(tenv, "sixel") {
+		return true
+	}
+	for _, t := range []string{"mlterm", "yaft", "foot", "contour"} {
+		if strings.HasPrefix(tenv, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// DrawSixelImage draws the image, at full pixel resolution, with its top left corner at the
+// sx, sy cell using Sixel graphics. Colors are quantized to the 216 web safe colors palette.
+func (ap *AnsiPixels) DrawSixelImage(sx, sy int, img *image.RGBA) error {
+	ap.MoveCursor(sx, sy)
+	var sb strings.Builder
+	encodeSixel(&sb, img)
+	_, err := ap.Out.WriteString(sb.String())
+	return err
+}
+
+// Returns the size of a cell in image pixels: the actual pixel size (as queried during setup, see
+// [CellPixelSize]) in Sixel and kitty graphics modes, 1 by 2 (half blocks) otherwise.
+func (ap *AnsiPixels) imageCellSize() (int, int) {
+	if !ap.Sixel && !ap.KittyGraphics {
+		return 1, 2
+	}
+	w, h, err := ap.CellPixelSize()
+	if err != nil || w <= 0 || h <= 0 {
+		log.Debugf("Unable to get cell size for images, assuming %dx%d: %v", defaultCellW, defaultCellH, err)
+		return defaultCellW, defaultCellH
+	}
+	return w, h
+}
+
+// Writes the Sixel sequence for img to sb: the palette of the colors used (in order of first
+// appearance) and then, for each band of 6 rows, one run length encoded line per color.
+func encodeSixel(sb *strings.Builder, img *image.RGBA) {
+	b := img.Bounds()
+	pimg := image.NewPaletted(b, palette.WebSafe)
+	draw.Draw(pimg, b, img, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+	fmt.Fprintf(sb, "\033Pq\"1;1;%d;%d", w, h)
+	var used [256]bool
+	var regs []uint8 // palette index of each sixel color register.
+	for y := range h {
+		for x := range w {
+			idx := pimg.ColorIndexAt(b.Min.X+x, b.Min.Y+y)
+			if used[idx] {
+				continue
+			}
+			used[idx] = true
+			c := palette.WebSafe[idx].(color.RGBA) //nolint:forcetypeassert // WebSafe colors are RGBA.
+			fmt.Fprintf(sb, "#%d;2;%d;%d;%d", len(regs), pct(c.R), pct(c.G), pct(c.B))
+			regs = append(regs, idx)
+		}
+	}
+	bits := make([]byte, w)
+	for band := 0; band < h; band += 6 {
+		if band > 0 {
+			sb.WriteByte('-')
+		}
+		first := true
+		for reg, idx := range regs {
+			empty := true
+			for x := range w {
+				bits[x] = 0
+				for i := 0; i < 6 && band+i < h; i++ {
+					if pimg.ColorIndexAt(b.Min.X+x, b.Min.Y+band+i) == idx {
+						bits[x] |= 1 << i
+						empty = false
+					}
+				}
+			}
+			if empty {
+				continue
+			}
+			if !first {
+				sb.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(sb, "#%d", reg)
+			writeSixelRuns(sb, bits)
+		}
+	}
+	sb.WriteString("\033\\")
+}
+
+// Writes the sixels, with runs of more than 3 identical ones as !count and trailing empty
+// ones omitted.
+func writeSixelRuns(sb *strings.Builder, bits []byte) {
+	end := len(bits)
+	for end > 0 && bits[end-1] == 0 {
+		end--
+	}
+	for i := 0; i < end; {
+		j := i + 1
+		for j < end && bits[j] == bits[i] {
+			j++
+		}
+		ch := byte('?' + bits[i])
+		if n := j - i; n > 3 {
+			fmt.Fprintf(sb, "!%d%c", n, ch)
+		} else {
+			for range n {
+				sb.WriteByte(ch)
+			}
+		}
+		i = j
+	}
+}
+
+// Converts a 0-255 component to the 0-100 sixel scale.
+func pct(v uint8) int {
+	return (int(v)*100 + 127) / 255
+}
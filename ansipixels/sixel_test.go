@@ -0,0 +1,35 @@
+package ansipixels
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSixel(t *testing.T) {
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 6))
+	for y := range 6 {
+		img.Set(0, y, red)
+		img.Set(1, y, blue)
+	}
+	var sb strings.Builder
+	encodeSixel(&sb, img)
+	expected := "\033Pq\"1;1;2;6#0;2;100;0;0#1;2;0;0;100#0~$#1?~\033\\"
+	if got := sb.String(); got != expected {
+		t.Errorf("unexpected sixel %q, expected %q", got, expected)
+	}
+	// 7 rows: 2 bands; runs; the near white is quantized to white.
+	img = image.NewRGBA(image.Rect(0, 0, 5, 7))
+	for x := range 5 {
+		img.Set(x, 0, color.RGBA{250, 250, 250, 255})
+		img.Set(x, 6, red)
+	}
+	sb.Reset()
+	encodeSixel(&sb, img)
+	expected = "\033Pq\"1;1;5;7#0;2;100;100;100#1;2;0;0;0#2;2;100;0;0#0!5@$#1!5}-#2!5@\033\\"
+	if got := sb.String(); got != expected {
+		t.Errorf("unexpected sixel %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,306 @@
+package ansipixels
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"fortio.org/term"
+	"github.com/rivo/uniseg"
+)
+
+// screenBuffer is the fixed size plain text "screen" used when stdout isn't a terminal: it
+// interprets the cursor movements and clears and ignores the other (color, mode) sequences.
+// It's also used for double buffering, see newStyledScreenBuffer.
+type screenBuffer struct {
+	cells   [][]string // one grapheme (or "" for the right half of a wide one) per cell.
+	x, y    int
+	partial []byte // incomplete escape sequence or utf-8 at the end of the last Write.
+	top     int    // scroll region (see SetScrollRegion), bottom is -1 for the last line.
+	bottom  int
+	// Only for styled buffers:
+	styles      [][]string // SGR sequence (see sgrState.String) of each cell.
+	sgr         sgrState
+	passthrough io.Writer // where the sequences other than cursor moves, erases and SGR go.
+}
+
+func newScreenBuffer(w, h int) *screenBuffer {
+	sb := &screenBuffer{cells: make([][]string, h), bottom: -1}
+	for i := range sb.cells {
+		sb.cells[i] = make([]string, w)
+	}
+	sb.clear()
+	return sb
+}
+
+func (sb *screenBuffer) clear() {
+	for y, row := range sb.cells {
+		sb.erase(y, 0, len(row))
+	}
+}
+
+// Erases (to spaces with the default style) the cells from x start to end (excluded) of row y.
+func (sb *screenBuffer) erase(y, start, end int) {
+	if y < 0 || y >= len(sb.cells) {
+		return
+	}
+	row := sb.cells[y]
+	for i := max(start, 0); i < min(end, len(row)); i++ {
+		row[i] = " "
+		if sb.styles != nil {
+			sb.styles[y][i] = ""
+		}
+	}
+}
+
+func (sb *screenBuffer) Write(buf []byte) (int, error) {
+	n := len(buf)
+	s := string(append(sb.partial, buf...))
+	sb.partial = nil
+	state := -1
+	for len(s) > 0 {
+		switch s[0] {
+		case 27:
+			if len(s) == 1 {
+				sb.partial = []byte(s)
+				return n, nil
+			}
+			if s[1] == ']' { // OSC (e.g. clipboard), skip until the terminator.
+				end := strings.IndexByte(s, '\a') + 1
+				if st := strings.Index(s, "\033\\"); st != -1 && (end == 0 || st+2 < end) {
+					end = st + 2
+				}
+				if end == 0 {
+					sb.partial = []byte(s) // unterminated, wait for the rest.
+					return n, nil
+				}
+				sb.pass(s[:end])
+				s = s[end:]
+				continue
+			}
+			loc := cleanAnsiRE.FindStringIndex(s)
+			if loc == nil || loc[0] != 0 {
+				s = s[1:] // not a CSI sequence, skip the escape.
+				continue
+			}
+			last := s[loc[1]-1]
+			if loc[1] == len(s) && (loc[1] == 2 || last < '@' || last > '~') {
+				sb.partial = []byte(s) // unterminated, wait for the rest.
+				return n, nil
+			}
+			sb.csi(s[2:loc[1]])
+			s = s[loc[1]:]
+			state = -1
+			continue
+		case '\r':
+			sb.x = 0
+			s = s[1:]
+			continue
+		case '\n':
+			if sb.y == sb.bottom {
+				sb.scroll(1) // at the bottom of an explicit scroll region.
+			} else {
+				sb.y++
+			}
+			s = s[1:]
+			continue
+		}
+		var g string
+		var w int
+		g, s, w, state = uniseg.FirstGraphemeClusterInString(s, state)
+		sb.put(g, w)
+	}
+	return n, nil
+}
+
+// Handles the content of a CSI sequence (after ESC[), e.g. "3;4H".
+func (sb *screenBuffer) csi(seq string) {
+	if seq == "" {
+		return
+	}
+	final := seq[len(seq)-1]
+	params := strings.Split(seq[:len(seq)-1], ";")
+	num := func(i int) int {
+		if i >= len(params) {
+			return 1
+		}
+		v, err := strconv.Atoi(params[i])
+		if err != nil || v == 0 {
+			return 1
+		}
+		return v
+	}
+	switch final {
+	case 'H':
+		sb.y, sb.x = num(0)-1, num(1)-1
+	case 'G':
+		sb.x = num(0) - 1
+	case 'A':
+		sb.y -= num(0)
+	case 'B':
+		sb.y += num(0)
+	case 'C':
+		sb.x += num(0)
+	case 'D':
+		sb.x -= num(0)
+	case 'J':
+		if params[0] == "2" {
+			sb.clear()
+		}
+	case 'X':
+		sb.erase(sb.y, sb.x, sb.x+num(0))
+	case 'K':
+		switch params[0] {
+		case "2":
+			sb.erase(sb.y, 0, math.MaxInt)
+		case "1":
+			sb.erase(sb.y, 0, sb.x+1)
+		default:
+			sb.erase(sb.y, sb.x, math.MaxInt)
+		}
+	case 'r':
+		sb.top, sb.bottom = num(0)-1, -1
+		if len(params) > 1 && params[1] != "" {
+			sb.bottom = num(1) - 1
+		}
+		sb.x, sb.y = 0, 0
+	case 'S':
+		sb.scroll(num(0))
+	case 'T':
+		sb.scroll(-num(0))
+	case 'm':
+		if sb.styles != nil {
+			sb.sgr.apply(params)
+		}
+	default:
+		sb.pass("\033[" + seq)
+	}
+}
+
+// Scrolls the lines of the scroll region up by n (down if n is negative), the new lines are blank.
+func (sb *screenBuffer) scroll(n int) {
+	top, bottom := sb.top, len(sb.cells)-1
+	if sb.bottom >= 0 {
+		bottom = min(sb.bottom, bottom)
+	}
+	if top < 0 || top >= bottom {
+		return
+	}
+	rotate := func(rows [][]string) {
+		if n > 0 {
+			first := rows[top]
+			copy(rows[top:bottom], rows[top+1:bottom+1])
+			rows[bottom] = first
+		} else {
+			last := rows[bottom]
+			copy(rows[top+1:bottom+1], rows[top:bottom])
+			rows[top] = last
+		}
+	}
+	for range min(abs(n), bottom-top+1) {
+		rotate(sb.cells)
+		if sb.styles != nil {
+			rotate(sb.styles)
+		}
+		if n > 0 {
+			sb.erase(bottom, 0, math.MaxInt)
+		} else {
+			sb.erase(top, 0, math.MaxInt)
+		}
+	}
+}
+
+// Sends a sequence not affecting the cells to the passthrough writer, if any.
+func (sb *screenBuffer) pass(seq string) {
+	if sb.passthrough != nil {
+		_, _ = io.WriteString(sb.passthrough, seq)
+	}
+}
+
+func (sb *screenBuffer) put(g string, w int) {
+	if sb.y >= 0 && sb.y < len(sb.cells) && sb.x >= 0 && sb.x+w <= len(sb.cells[sb.y]) {
+		row := sb.cells[sb.y]
+		row[sb.x] = g
+		for i := 1; i < w; i++ {
+			row[sb.x+i] = ""
+		}
+		if sb.styles != nil {
+			st := sb.sgr.String()
+			for i := range w {
+				sb.styles[sb.y][sb.x+i] = st
+			}
+		}
+	}
+	sb.x += w
+}
+
+// String returns the screen content, with trailing spaces removed.
+func (sb *screenBuffer) String() string {
+	var res strings.Builder
+	for _, row := range sb.cells {
+		res.WriteString(strings.TrimRight(strings.Join(row, ""), " "))
+		res.WriteByte('\n')
+	}
+	return res.String()
+}
+
+// Returned by the terminal queries (ReadCursorPos...) in snapshot mode.
+var errSnapshotMode = errors.New("not a terminal (snapshot mode)")
+
+// SnapshotMode is true when stdout isn't a terminal: drawing then goes to a fixed size
+// plain text buffer, which is written to stdout by Restore (see [Snapshot]).
+func (ap *AnsiPixels) SnapshotMode() bool {
+	return ap.snapshot != nil
+}
+
+// Snapshot returns the current plain text content of the screen in snapshot mode
+// (empty string otherwise).
+func (ap *AnsiPixels) Snapshot() string {
+	if ap.snapshot == nil {
+		return ""
+	}
+	_ = ap.Out.Flush()
+	return ap.snapshot.String()
+}
+
+// RenderTo runs fn with the output going to w instead of the terminal, for instance to capture
+// a frame to a file in the middle of a session, and then restores the terminal output (pending
+// output is flushed to the terminal first). The cursor position and the [SetMaxBytesPerSecond]
+// throttling (not applied to w) are also restored. Not safe to call concurrently with other
+// drawing on ap.
+func (ap *AnsiPixels) RenderTo(w io.Writer, fn func()) error {
+	_ = ap.Out.Flush()
+	out, throttle, x, y := ap.Out, ap.throttle, ap.x, ap.y
+	defer func() {
+		ap.Out, ap.throttle, ap.x, ap.y = out, throttle, x, y
+	}()
+	ap.Out = bufio.NewWriterSize(w, ap.Out.Size())
+	ap.throttle = nil
+	fn()
+	return ap.Out.Flush()
+}
+
+// Switches to snapshot mode, the size is from $COLUMNS and $LINES or 80x24 by default.
+func (ap *AnsiPixels) openSnapshot() (err error) {
+	ap.W, ap.H = envInt("COLUMNS", 80), envInt("LINES", 24)
+	ap.snapshot = newScreenBuffer(ap.W, ap.H)
+	_ = ap.Out.Flush()
+	ap.Out.Reset(ap.snapshot)
+	if term.IsTerminal(ap.FdIn) {
+		// Keys can still be read from the terminal.
+		ap.state, err = term.MakeRaw(ap.FdIn)
+	}
+	return err
+}
+
+func envInt(name string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package ansipixels
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScreenBuffer(t *testing.T) {
+	sb := newScreenBuffer(10, 3)
+	ap := &AnsiPixels{W: 10, H: 3, Out: bufio.NewWriter(sb), snapshot: sb}
+	ap.ClearScreen()
+	ap.WriteAtStr(2, 1, Red+"hello"+Reset)
+	ap.WriteAtStr(0, 2, "日本")
+	ap.CopyToClipboard("not shown")
+	ap.MoveCursor(8, 0)
+	ap.WriteString("abcd") // clipped.
+	expected := "        ab\n  hello\n日本\n"
+	if got := ap.Snapshot(); got != expected {
+		t.Errorf("unexpected snapshot %q, expected %q", got, expected)
+	}
+	// Split escape sequence across writes.
+	_, _ = sb.Write([]byte("\033[2;"))
+	_, _ = sb.Write([]byte("1Hx\033"))
+	_, _ = sb.Write([]byte("[Ky"))
+	if got := sb.String(); got != "        ab\nxy\n日本\n" {
+		t.Errorf("unexpected snapshot after split sequences %q", got)
+	}
+	// Queries fail right away (In is nil, it would panic if read) and don't write to the snapshot.
+	if _, _, err := ap.ReadCursorPos(); !errors.Is(err, errSnapshotMode) {
+		t.Errorf("expected the snapshot mode error, got %v", err)
+	}
+	if got := ap.Snapshot(); got != "        ab\nxy\n日本\n" {
+		t.Errorf("unexpected snapshot after a query %q", got)
+	}
+}
+
+func TestRenderTo(t *testing.T) {
+	var live strings.Builder
+	ap := &AnsiPixels{W: 10, H: 2, Out: bufio.NewWriter(&live)}
+	ap.WriteAtStr(0, 0, "live")
+	frame := newScreenBuffer(10, 2)
+	err := ap.RenderTo(frame, func() {
+		ap.WriteAtStr(1, 1, "frame")
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ap.WriteString("!")
+	_ = ap.Out.Flush()
+	if got := frame.String(); got != "\n frame\n" {
+		t.Errorf("unexpected frame %q", got)
+	}
+	if got := live.String(); strings.Contains(got, "frame") || !strings.HasSuffix(got, "live!") {
+		t.Errorf("unexpected live output %q", got)
+	}
+}
+
+func TestWriteCenteredTruncate(t *testing.T) {
+	sb := newScreenBuffer(8, 2)
+	ap := &AnsiPixels{W: 8, H: 2, Out: bufio.NewWriter(sb), snapshot: sb}
+	ap.WriteCentered(0, "hi")
+	ap.WriteCentered(1, "%s", Green+"much too long"+Reset)
+	if got, expected := ap.Snapshot(), "   hi\nmuch to…\n"; got != expected {
+		t.Errorf("unexpected snapshot %q, expected %q", got, expected)
+	}
+	ap.SetEllipsis("...")
+	if got, w := ap.TruncateRightToFit("much too long", 8); got != "much ..." || w != 8 {
+		t.Errorf("unexpected right truncation %q %d", got, w)
+	}
+	if got, w := ap.TruncateLeftToFit("much too long", 8); got != "... long" || w != 8 {
+		t.Errorf("unexpected left truncation %q %d", got, w)
+	}
+}
+
+func TestWriteDiffLine(t *testing.T) {
+	var out strings.Builder
+	ap := &AnsiPixels{W: 8, H: 3, Out: bufio.NewWriter(&out)}
+	ap.WriteDiffLine(0, DiffAdded, "a\tb")
+	ap.WriteDiffLine(1, DiffRemoved, "much too long")
+	_ = ap.Out.Flush()
+	sb := newScreenBuffer(8, 3)
+	_, _ = sb.Write([]byte(out.String()))
+	if got, expected := sb.String(), "+a    b\n-much t…\n\n"; got != expected {
+		t.Errorf("unexpected snapshot %q, expected %q", got, expected)
+	}
+	if !strings.Contains(out.String(), "+a    b "+Reset) {
+		t.Errorf("expected the line to be padded to the full width: %q", out.String())
+	}
+}
@@ -0,0 +1,89 @@
+package ansipixels
+
+import (
+	"fmt"
+	"time"
+
+	"fortio.org/fortio/stats"
+)
+
+// Frame timing statistics for [AnsiPixels.DrawStatsOverlay].
+type frameStats struct {
+	hist    *stats.Histogram // frame durations in milliseconds.
+	last    time.Time
+	start   time.Time
+	current time.Duration // last frame duration.
+	dropped int64         // frames that took more than 1.5 times the target frame duration.
+}
+
+// RecordFrame records the time since the previous call as a frame duration, and returns it
+// (0 for the first call). Call it once per frame (e.g. after each EndSyncMode) for the stats of
+// [DrawStatsOverlay] and [FrameHistogram].
+func (ap *AnsiPixels) RecordFrame() time.Duration {
+	now := time.Now()
+	if ap.frames == nil {
+		ap.ResetFrameStats()
+	}
+	fs := ap.frames
+	if fs.last.IsZero() {
+		fs.last = now
+		fs.start = now
+		return 0
+	}
+	fs.current = now.Sub(fs.last)
+	fs.last = now
+	ms := float64(fs.current) / float64(time.Millisecond)
+	fs.hist.Record(ms)
+	if ap.FPS > 0 && ms > 1.5*1000./ap.FPS {
+		fs.dropped++
+	}
+	return fs.current
+}
+
+// FrameHistogram returns the histogram of frame durations, in milliseconds, recorded by
+// [RecordFrame] (nil if it was never called), e.g. to log the p99 frame time:
+//
+//	h := ap.FrameHistogram().Export().CalcPercentiles([]float64{99})
+//	log.Infof("p99 frame time %.2f ms", h.Percentiles[0].Value)
+func (ap *AnsiPixels) FrameHistogram() *stats.Histogram {
+	if ap.frames == nil {
+		return nil
+	}
+	return ap.frames.hist
+}
+
+// ResetFrameStats clears the frame statistics (e.g. after a pause).
+func (ap *AnsiPixels) ResetFrameStats() {
+	ap.frames = &frameStats{hist: stats.NewHistogram(0, 0.1)}
+}
+
+// DrawStatsOverlay draws, boxed in the top right corner, the current and average FPS,
+// frame time percentiles and number of dropped (late) frames, as recorded by [RecordFrame].
+func (ap *AnsiPixels) DrawStatsOverlay() {
+	fs := ap.frames
+	if fs == nil || fs.hist.Count == 0 {
+		return
+	}
+	cur := 0.
+	if fs.current > 0 {
+		cur = float64(time.Second) / float64(fs.current)
+	}
+	avg := float64(fs.hist.Count) / fs.last.Sub(fs.start).Seconds()
+	lines := []string{fmt.Sprintf("FPS %.1f avg %.1f", cur, avg)}
+	data := fs.hist.Export()
+	data.CalcPercentiles([]float64{50, 90, 99})
+	for _, p := range data.Percentiles {
+		lines = append(lines, fmt.Sprintf("p%-2g %.2f ms", p.Percentile, p.Value))
+	}
+	lines = append(lines, fmt.Sprintf("Dropped %d/%d", fs.dropped, fs.hist.Count))
+	maxw := 0
+	for _, l := range lines {
+		maxw = max(maxw, len(l))
+	}
+	p := ap.NewPanel(ap.W-maxw-2, 0, maxw+2, len(lines)+2)
+	p.Clear()
+	p.DrawRoundBox()
+	for i, l := range lines {
+		p.WriteAtStr(1, i+1, l)
+	}
+}
@@ -0,0 +1,80 @@
+package ansipixels
+
+import "strings"
+
+// Style is a combination of attributes (e.g. [Bold], [Italic]) and colors (e.g. [Red] or a 256
+// colors sequence like [Orange]) as a single ansi sequence.
+type Style string
+
+// NewStyle combines the given attributes and colors codes into a single Style.
+// For instance NewStyle(Bold, Italic, Red).Apply("Error").
+func NewStyle(codes ...string) Style {
+	params := make([]string, 0, len(codes))
+	for _, c := range codes {
+		p, okStart := strings.CutPrefix(c, "\033[")
+		p, okEnd := strings.CutSuffix(p, "m")
+		if !okStart || !okEnd {
+			return Style(strings.Join(codes, "")) // not only SGR sequences, can't merge.
+		}
+		// Already combined codes, e.g. Orange+Bold.
+		params = append(params, strings.ReplaceAll(p, "m\033[", ";"))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return Style("\033[" + strings.Join(params, ";") + "m")
+}
+
+// Apply returns s wrapped in the style, followed by [Reset].
+func (st Style) Apply(s string) string {
+	if st == "" {
+		return s
+	}
+	return string(st) + s + Reset
+}
+
+// StyleStack tracks nested styles so ending an inner style restores the enclosing ones instead
+// of resetting everything, e.g. a colored cell inside a styled table row. The zero value is an
+// empty stack, ready to use.
+type StyleStack struct {
+	styles []Style
+}
+
+// Push adds st on top of the stack and returns the sequence to output to start it.
+func (ss *StyleStack) Push(st Style) string {
+	ss.styles = append(ss.styles, st)
+	return string(st)
+}
+
+// Pop removes the innermost style and returns the sequence to output to restore the enclosing
+// styles (a [Reset] followed by the remaining styles).
+func (ss *StyleStack) Pop() string {
+	if len(ss.styles) > 0 {
+		ss.styles = ss.styles[:len(ss.styles)-1]
+	}
+	return ss.Current()
+}
+
+// Current returns the sequence setting the combination of all the styles in the stack
+// (starting with a [Reset]).
+func (ss *StyleStack) Current() string {
+	var sb strings.Builder
+	sb.WriteString(Reset)
+	for _, st := range ss.styles {
+		sb.WriteString(string(st))
+	}
+	return sb.String()
+}
+
+// Depth returns the number of styles in the stack.
+func (ss *StyleStack) Depth() int {
+	return len(ss.styles)
+}
+
+// Apply returns s in style st, nested within the current styles: [Reset] inside s and at
+// the end restore the enclosing styles instead of turning them all off.
+func (ss *StyleStack) Apply(st Style, s string) string {
+	start := ss.Push(st)
+	s = strings.ReplaceAll(s, Reset, ss.Current())
+	return start + s + ss.Pop()
+}
@@ -0,0 +1,43 @@
+package ansipixels
+
+import "testing"
+
+func TestStyle(t *testing.T) {
+	tests := []struct {
+		style    Style
+		expected string
+	}{
+		{NewStyle(), "x"},
+		{NewStyle(Bold, Italic, Red), "\033[1;3;31mx\033[0m"},
+		{NewStyle(Orange + Bold), "\033[38;5;214;1mx\033[0m"},
+		{NewStyle(Strikethrough, MoveLeft), "\033[9m\033[1Dx\033[0m"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.Apply("x"); got != tt.expected {
+			t.Errorf("Apply(%q) = %q, expected %q", tt.style, got, tt.expected)
+		}
+	}
+}
+
+func TestStyleStack(t *testing.T) {
+	var ss StyleStack
+	row := NewStyle(Reverse)
+	cell := NewStyle(Red)
+	start := ss.Push(row)
+	got := start + ss.Apply(cell, "a"+Bold+"b"+Reset+"c") + "d" + ss.Pop()
+	expected := "\033[7m\033[31ma\033[1mb\033[0m\033[7m\033[31mc\033[0m\033[7md\033[0m"
+	if got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+	if ss.Depth() != 0 {
+		t.Errorf("expected empty stack, got %d", ss.Depth())
+	}
+}
+
+func TestMarkup(t *testing.T) {
+	got := Markup("[red]error[/] and [bold italic]b [green]g[/] b[/] [[x] [nope] [dim]open")
+	expected := "\033[31merror\033[0m and \033[1;3mb \033[32mg\033[0m\033[1;3m b\033[0m [x] [nope] \033[2mopen\033[0m"
+	if got != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
@@ -0,0 +1,159 @@
+package ansipixels
+
+import (
+	"math"
+	"strings"
+)
+
+// TextView is a scrollable, word wrapped, view of some text lines (e.g. for help or logs).
+type TextView struct {
+	Lines   []string // Text to show (one entry per line, without \n).
+	Top     int      // First visible (wrapped) line.
+	wrapped []string
+	wrapW   int // width used for wrapped, 0 when it needs to be recomputed.
+	height  int // height of the last Draw, for paging.
+}
+
+// NewTextView creates a TextView for the given (multi line) text.
+func NewTextView(text string) *TextView {
+	tv := &TextView{}
+	tv.SetText(text)
+	return tv
+}
+
+// SetText replaces the content of the view and scrolls back to the top.
+func (tv *TextView) SetText(text string) {
+	tv.SetLines(strings.Split(text, "\n"))
+}
+
+// SetLines is like SetText for already split lines.
+func (tv *TextView) SetLines(lines []string) {
+	tv.Lines = lines
+	tv.Top = 0
+	tv.wrapW = 0
+}
+
+// AppendLines adds lines at the end, keeping the view at the end if it was already showing it
+// (log tail behavior).
+func (tv *TextView) AppendLines(lines ...string) {
+	atEnd := tv.wrapW == 0 || tv.Top >= len(tv.wrapped)-tv.height
+	tv.Lines = append(tv.Lines, lines...)
+	tv.wrapW = 0
+	if atEnd {
+		tv.End()
+	}
+}
+
+// Scrolling functions, by one line or one page (of the last Draw's height).
+func (tv *TextView) ScrollUp()   { tv.scrollTo(tv.Top - 1) }
+func (tv *TextView) ScrollDown() { tv.scrollTo(tv.Top + 1) }
+func (tv *TextView) PageUp()     { tv.scrollTo(tv.Top - max(1, tv.height-1)) }
+func (tv *TextView) PageDown()   { tv.scrollTo(tv.Top + max(1, tv.height-1)) }
+func (tv *TextView) Home()       { tv.scrollTo(0) }
+func (tv *TextView) End()        { tv.scrollTo(math.MaxInt) }
+
+func (tv *TextView) scrollTo(top int) {
+	tv.Top = top
+	if tv.wrapW != 0 { // otherwise it'll be clamped in Draw.
+		tv.Top = min(max(tv.Top, 0), max(0, len(tv.wrapped)-tv.height))
+	}
+}
+
+// HandleKey scrolls the view for arrow up/down, page up/down, home and end keys
+// in data (typically ap.Data). Returns true if the key was handled.
+func (tv *TextView) HandleKey(data []byte) bool {
+	switch string(data) {
+	case "\033[A", "\033OA":
+		tv.ScrollUp()
+	case "\033[B", "\033OB":
+		tv.ScrollDown()
+	case "\033[5~":
+		tv.PageUp()
+	case "\033[6~", " ":
+		tv.PageDown()
+	case "\033[H", "\033OH", "\033[1~":
+		tv.Home()
+	case "\033[F", "\033OF", "\033[4~":
+		tv.End()
+	default:
+		return false
+	}
+	return true
+}
+
+// Draw shows the view in the w x h rectangle at x, y with, if the text doesn't fit,
+// a scrollbar in the last column.
+func (tv *TextView) Draw(ap *AnsiPixels, x, y, w, h int) {
+	if w <= 1 || h <= 0 {
+		return
+	}
+	tv.height = h
+	if tv.wrapW != w-1 || len(tv.wrapped) <= h {
+		tv.wrap(ap, w)
+	}
+	scrollbar := len(tv.wrapped) > h
+	if scrollbar {
+		tv.wrap(ap, w-1)
+	}
+	tv.scrollTo(tv.Top)
+	p := ap.NewPanel(x, y, w, h)
+	p.Clear()
+	for i := range min(h, len(tv.wrapped)-tv.Top) {
+		p.WriteAtStr(0, i, tv.wrapped[tv.Top+i]+Reset)
+	}
+	if !scrollbar {
+		return
+	}
+	// Thumb size and position proportional to the visible part.
+	thumb := max(1, h*h/len(tv.wrapped))
+	pos := tv.Top * (h - thumb) / (len(tv.wrapped) - h)
+	for i := range h {
+		r := '░'
+		if i >= pos && i < pos+thumb {
+			r = FullPixel
+		}
+		p.MoveCursor(w-1, i)
+		ap.WriteRune(r)
+	}
+}
+
+func (tv *TextView) wrap(ap *AnsiPixels, width int) {
+	if tv.wrapW == width {
+		return
+	}
+	tv.wrapW = width
+	tv.wrapped = tv.wrapped[:0]
+	for _, l := range tv.Lines {
+		tv.wrapped = append(tv.wrapped, WrapLine(ap, l, width)...)
+	}
+}
+
+// WrapLine splits line on spaces into lines no wider than width screen columns.
+// Words longer than width are split.
+func WrapLine(ap *AnsiPixels, line string, width int) []string {
+	if ap.ScreenWidth(line) <= width {
+		return []string{line}
+	}
+	var res []string
+	cur, curW := "", 0
+	started := false
+	for _, word := range strings.Split(line, " ") {
+		ww := ap.ScreenWidth(word)
+		if started && curW+1+ww <= width {
+			cur += " " + word
+			curW += 1 + ww
+			continue
+		}
+		if started {
+			res = append(res, cur)
+		}
+		started = true
+		for ww > width {
+			res = append(res, clipColumns(word, 0, width))
+			word = clipColumns(word, width, ww)
+			ww = ap.ScreenWidth(word)
+		}
+		cur, curW = word, ww
+	}
+	return append(res, cur)
+}
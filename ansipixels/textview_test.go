@@ -0,0 +1,102 @@
+package ansipixels
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWrapLine(t *testing.T) {
+	ap := &AnsiPixels{}
+	got := WrapLine(ap, "  the quick brown fox jumps", 10)
+	expected := []string{"  the", "quick", "brown fox", "jumps"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("WrapLine got %q, expected %q", got, expected)
+	}
+	got = WrapLine(ap, "abcdefghij12345 x", 5)
+	expected = []string{"abcde", "fghij", "12345", "x"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("WrapLine long word got %q, expected %q", got, expected)
+	}
+}
+
+func TestTextViewScroll(t *testing.T) {
+	sb := newScreenBuffer(6, 4)
+	ap := &AnsiPixels{W: 6, H: 4, Out: bufio.NewWriter(sb), snapshot: sb}
+	lines := make([]string, 10)
+	for i := range lines {
+		lines[i] = "l" + strconv.Itoa(i)
+	}
+	tv := &TextView{}
+	tv.SetLines(lines)
+	tv.Top = 100 // clamped by Draw.
+	// Checks the visible lines, starting at first, and the scrollbar thumb position.
+	check := func(what string, first, thumb int) {
+		t.Helper()
+		tv.Draw(ap, 0, 0, 6, 4)
+		if tv.Top != first {
+			t.Errorf("%s: Top %d, expected %d", what, tv.Top, first)
+		}
+		var expected strings.Builder
+		for i := range 4 {
+			bar := '░'
+			if i == thumb {
+				bar = FullPixel
+			}
+			fmt.Fprintf(&expected, "%-5s%c\n", lines[first+i], bar)
+		}
+		if got := ap.Snapshot(); got != expected.String() {
+			t.Errorf("%s: got %q, expected %q", what, got, expected.String())
+		}
+	}
+	check("clamped initial offset", 6, 3)
+	tv.ScrollDown()
+	check("scroll down at the end", 6, 3)
+	tv.Home()
+	check("home", 0, 0)
+	tv.ScrollUp()
+	check("scroll up at the top", 0, 0)
+	tv.ScrollDown()
+	check("scroll down", 1, 0)
+	tv.PageDown()
+	check("page down", 4, 2)
+	tv.PageDown()
+	check("page down to the end", 6, 3)
+	tv.PageUp()
+	check("page up", 3, 1)
+	tv.PageUp()
+	check("page up to the top", 0, 0)
+	if !tv.HandleKey([]byte("\033[F")) || tv.HandleKey([]byte("x")) {
+		t.Errorf("unexpected HandleKey results")
+	}
+	check("end key", 6, 3)
+	if !tv.HandleKey([]byte("\033[5~")) {
+		t.Errorf("page up key not handled")
+	}
+	check("page up key", 3, 1)
+	// Appending while at the end follows the tail, not otherwise.
+	tv.End()
+	lines = append(lines, "l10")
+	tv.AppendLines("l10")
+	tv.Draw(ap, 0, 0, 6, 4)
+	if tv.Top != 7 {
+		t.Errorf("expected to follow the tail, Top %d", tv.Top)
+	}
+	tv.Home()
+	tv.AppendLines("l11")
+	tv.Draw(ap, 0, 0, 6, 4)
+	if tv.Top != 0 {
+		t.Errorf("expected to stay at the top, Top %d", tv.Top)
+	}
+	// Text fitting: no scrolling nor scrollbar.
+	tv.SetText("a\nb")
+	tv.End()
+	tv.PageDown()
+	tv.Draw(ap, 0, 0, 6, 4)
+	if got := ap.Snapshot(); tv.Top != 0 || got != "a\nb\n\n\n" {
+		t.Errorf("unexpected short text view, Top %d: %q", tv.Top, got)
+	}
+}
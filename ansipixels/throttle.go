@@ -0,0 +1,88 @@
+package ansipixels
+
+import (
+	"bufio"
+	"bytes"
+	"time"
+
+	"fortio.org/log"
+)
+
+// throttledWriter holds the output of a frame (between StartSyncMode and EndSyncMode) and only
+// sends it if it fits in the bytes per second budget, dropping it otherwise.
+type throttledWriter struct {
+	out     *bufio.Writer // the output before throttling, restored when turned off.
+	buf     bytes.Buffer
+	inFrame bool
+	maxBps  float64
+	tokens  float64 // available budget in bytes (token bucket, up to 1 second worth).
+	last    time.Time
+	dropped int64
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	if !tw.inFrame {
+		n, err := tw.out.Write(p)
+		if err != nil {
+			return n, err
+		}
+		return n, tw.out.Flush()
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *throttledWriter) endFrame() error {
+	if !tw.inFrame {
+		return nil
+	}
+	tw.inFrame = false
+	now := time.Now()
+	tw.tokens = min(tw.maxBps, tw.tokens+now.Sub(tw.last).Seconds()*tw.maxBps)
+	tw.last = now
+	n := float64(tw.buf.Len())
+	defer tw.buf.Reset()
+	// Frames larger than the whole budget still get sent when the bucket is full (going into debt).
+	if n > tw.tokens && tw.tokens < tw.maxBps {
+		tw.dropped++
+		log.Debugf("Dropping frame of %d bytes, budget %.0f (%d dropped so far)", tw.buf.Len(), tw.tokens, tw.dropped)
+		return nil
+	}
+	tw.tokens -= n
+	if _, err := tw.out.Write(tw.buf.Bytes()); err != nil {
+		return err
+	}
+	return tw.out.Flush()
+}
+
+// SetMaxBytesPerSecond limits the output bandwidth, e.g. for slow ssh links, to about n bytes
+// per second: frames (output between StartSyncMode and EndSyncMode) that don't fit in the budget
+// are dropped instead of queued. Output outside of sync mode is never dropped. This is meant for
+// apps redrawing the whole screen each frame, as a dropped incremental update would be lost.
+// 0 (or negative) removes the limit. Does nothing in [SnapshotMode].
+func (ap *AnsiPixels) SetMaxBytesPerSecond(n int) {
+	if ap.snapshot != nil {
+		return
+	}
+	if ap.throttle != nil {
+		_ = ap.Out.Flush()
+		tw := ap.throttle
+		tw.inFrame = false
+		_, _ = tw.out.Write(tw.buf.Bytes()) // send anything pending.
+		_ = tw.out.Flush()
+		ap.Out = tw.out
+		ap.throttle = nil
+	}
+	if n <= 0 {
+		return
+	}
+	ap.throttle = &throttledWriter{out: ap.Out, maxBps: float64(n), tokens: float64(n), last: time.Now()}
+	ap.Out = bufio.NewWriterSize(ap.throttle, ap.Out.Size())
+}
+
+// DroppedFrames returns the number of frames dropped because of [SetMaxBytesPerSecond].
+func (ap *AnsiPixels) DroppedFrames() int64 {
+	if ap.throttle == nil {
+		return 0
+	}
+	return ap.throttle.dropped
+}
@@ -0,0 +1,69 @@
+package ansipixels
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestThrottleRestoresOutput(t *testing.T) {
+	var out strings.Builder
+	ap := &AnsiPixels{Out: bufio.NewWriter(&out)}
+	orig := ap.Out
+	ap.SetMaxBytesPerSecond(0) // not throttling: nothing to undo.
+	if ap.Out != orig {
+		t.Errorf("output changed by SetMaxBytesPerSecond(0) without throttling")
+	}
+	ap.SetMaxBytesPerSecond(100)
+	ap.WriteString("abc") // outside of a frame, never dropped.
+	for range 2 {
+		ap.StartSyncMode()
+		ap.WriteString(strings.Repeat("x", 40))
+		ap.EndSyncMode()
+	}
+	if ap.DroppedFrames() != 1 {
+		t.Errorf("expected the frame over the budget to be dropped, got %d dropped", ap.DroppedFrames())
+	}
+	ap.SetMaxBytesPerSecond(0)
+	if ap.Out != orig {
+		t.Errorf("output not restored when turning throttling off")
+	}
+	ap.WriteString("def")
+	_ = ap.Out.Flush()
+	frame := "\033[?2026h" + strings.Repeat("x", 40) + "\033[?2026l"
+	if out.String() != "abc"+frame+"def" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	// In snapshot mode there is nothing to throttle, the output must stay the screen buffer.
+	sb := newScreenBuffer(10, 2)
+	ap = &AnsiPixels{W: 10, H: 2, Out: bufio.NewWriter(sb), snapshot: sb}
+	ap.SetMaxBytesPerSecond(100)
+	ap.SetMaxBytesPerSecond(0)
+	ap.WriteAtStr(0, 0, "hello")
+	if got := ap.Snapshot(); got != "hello\n\n" {
+		t.Errorf("unexpected snapshot %q", got)
+	}
+}
+
+func TestQueryWhileThrottled(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	var out strings.Builder
+	ap := &AnsiPixels{Out: bufio.NewWriter(&out), In: r}
+	ap.SetMaxBytesPerSecond(1000)
+	ap.StartSyncMode()
+	ap.WriteString("frame")
+	_, _ = w.WriteString("\033[12;34R")
+	x, y, err := ap.ReadCursorPos() // would block forever if the request was held with the frame.
+	if err != nil || x != 12 || y != 34 {
+		t.Errorf("unexpected cursor position %d, %d, %v", x, y, err)
+	}
+	if out.String() != "\033[?2026hframe\033[?2026l\033[6n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
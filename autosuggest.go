@@ -0,0 +1,93 @@
+package terminal
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"fortio.org/term"
+)
+
+// Private use rune injected in the input, instead of the key accepting the suggestion, to get
+// the term editor to call autoComplete and replace the line by the suggestion.
+const acceptSuggestionKey = '\uf8fd'
+
+const (
+	ghostStart = "\033[2m"  // dim.
+	ghostEnd   = "\033[22m" // normal intensity.
+)
+
+// SetAutoSuggest turns on (or off) fish style auto suggestions: while typing at the end of the
+// line, the rest of the most recent history entry starting with the line is shown dimmed after the
+// cursor and can be accepted with Right arrow or Control-E. Only the keys typed (not pasted,
+// completed or recalled lines) trigger a new suggestion.
+func (t *Terminal) SetAutoSuggest(enabled bool) {
+	if !t.IsTerminal() {
+		return
+	}
+	t.autoSuggest = enabled
+	if !enabled {
+		t.suggestion = ""
+		t.statusW.setGhost("")
+	}
+}
+
+// Returns the remainder of the most recent history entry starting with line (empty if none).
+func (t *Terminal) suggest(line string) string {
+	if line == "" {
+		return ""
+	}
+	for h := range t.HistoryAll() {
+		if len(h) > len(line) && strings.HasPrefix(h, line) && !strings.ContainsAny(h, "\r\n") {
+			return h[len(line):]
+		}
+	}
+	return ""
+}
+
+// Updates the suggestion after key was handled by autoComplete (ok true) or is about
+// to be inserted by the editor.
+func (t *Terminal) updateSuggestion(line string, pos int, key rune, newLine string, newPos int, ok bool) {
+	t.suggestion = ""
+	if !ok {
+		if key < ' ' || key >= 0xd800 && key <= 0xdbff { // not printable, see term.isPrintable.
+			return
+		}
+		newLine, newPos = line[:pos]+string(key)+line[pos:], pos+utf8.RuneLen(key)
+	}
+	if newPos != len(newLine) {
+		return
+	}
+	ghost := t.suggest(newLine)
+	if ghost == "" {
+		return
+	}
+	// Only show what fits on the current line (the editor doesn't know about the ghost text).
+	if w, _, err := term.GetSize(t.fdOut); err == nil {
+		avail := w - 1 - utf8.RuneCountInString(t.currentPrompt()) - utf8.RuneCountInString(newLine)
+		if t.rprompt != "" {
+			avail -= screenWidth(t.rprompt) + 1
+		}
+		if avail <= 0 {
+			return
+		}
+		if r := []rune(ghost); len(r) > avail {
+			ghost = string(r[:avail])
+		}
+	}
+	t.suggestion = newLine + ghost
+	t.statusW.setGhost(ghost)
+}
+
+// Called with each input chunk before the editor sees it: erases the ghost text (still
+// at the end of the line) and replaces accept keys by acceptSuggestionKey.
+func (t *Terminal) suggestionInput(in []byte) []byte {
+	if !t.statusW.eraseGhost() || t.suggestion == "" {
+		return in
+	}
+	switch string(in) {
+	case "\033[C", "\033OC", "\x05": // Right arrow, Control-E.
+		return []byte(string(acceptSuggestionKey))
+	}
+	t.suggestion = ""
+	return in
+}
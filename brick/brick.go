@@ -6,7 +6,6 @@ import (
 	"math"
 	"math/rand/v2"
 	"os"
-	"strings"
 	"time"
 
 	"fortio.org/cli"
@@ -72,6 +71,9 @@ const (
 	PaddleWidth  = 7
 	// Ball     = "⚾" // or "◯" or "⚫" doesn't work well/jerky movement, ⚪ is even worse as double width. so we use 1/2 blocks instead.
 	PaddleSpinFactor = 0.7
+	// Minimum terminal size to play: at least 2 bricks wide and room for the bricks, paddle and borders.
+	MinWidth  = 2*(BrickWidth+1) + 2
+	MinHeight = 16
 )
 
 // height and width in full height blocks (unlike images/life) for most but the ball.
@@ -386,6 +388,7 @@ func Main() int { //nolint:funlen // many flags etc...
 		return log.FErrf("Error opening AnsiPixels: %v", err)
 	}
 	defer ap.Restore()
+	defer ap.RestoreOnPanic()
 	ap.HideCursor()
 	ap.Margin = 1
 	if *replay != "" {
@@ -397,9 +400,16 @@ func Main() int { //nolint:funlen // many flags etc...
 	}
 	var b *Brick
 	restarted := false
+	tooSmall := false
 	ap.OnResize = func() error {
 		ap.ClearScreen()
 		ap.StartSyncMode()
+		restarted = true
+		tooSmall = !ap.EnsureMinSize(MinWidth, MinHeight)
+		if tooSmall {
+			ap.EndSyncMode()
+			return nil
+		}
 		prevInfo := false
 		if b != nil {
 			prevInfo = b.ShowInfo
@@ -412,10 +422,21 @@ func Main() int { //nolint:funlen // many flags etc...
 		ap.WriteCentered(ap.H/2+2, "Left A, Stop: S, Right: D - Quit: ^C or Q")
 		showInfo(ap, b)
 		ap.EndSyncMode()
-		restarted = true
 		return nil
 	}
+	// Returns true if the user quit while waiting for the terminal to be big enough.
+	waitForSize := func() bool {
+		for tooSmall {
+			if ap.ReadOrResizeOrSignal() != nil || (len(ap.Data) > 0 && (ap.Data[0] == 3 || ap.Data[0] == 'Q')) {
+				return true
+			}
+		}
+		return false
+	}
 	_ = ap.OnResize()
+	if waitForSize() {
+		return 0
+	}
 	err = ap.ReadOrResizeOrSignal()
 	if err != nil {
 		return log.FErrf("Error reading: %v", err)
@@ -448,6 +469,9 @@ func Main() int { //nolint:funlen // many flags etc...
 			return log.FErrf("Error reading: %v", err)
 		}
 		if restarted {
+			if waitForSize() {
+				return 0
+			}
 			_ = ap.ReadOrResizeOrSignal()
 		}
 		if handleKeys(ap, b, restarted /* handle pauses */) {
@@ -513,7 +537,6 @@ func handleKeys(ap *ansipixels.AnsiPixels, b *Brick, noPause bool) bool {
 		for {
 			msg := "⏱️ Paused, any key to resume... ⏱️"
 			mlen := ap.ScreenWidth(msg)
-			erase := strings.Repeat(" ", mlen)
 			x := (ap.W - mlen) / 2
 			y := ap.H/2 - 1
 			switch n % 20 {
@@ -521,8 +544,7 @@ func handleKeys(ap *ansipixels.AnsiPixels, b *Brick, noPause bool) bool {
 				ap.MoveCursor(x, y)
 				ap.WriteString(msg)
 			case 10:
-				ap.MoveCursor(x, y)
-				ap.WriteString(erase)
+				ap.EraseRect(x, y, mlen, 1)
 			}
 			ap.EndSyncMode()
 			r, _ := ap.ReadOrResizeOrSignalOnce()
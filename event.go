@@ -0,0 +1,135 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+)
+
+// EventType is the kind of an [Event].
+type EventType int
+
+const (
+	KeyEvent    EventType = iota // a key press, see Event.Key.
+	MouseEvent                   // a mouse event, see Event.X, Y and Buttons.
+	ResizeEvent                  // the terminal was resized, see Event.X, Y (new width and height).
+	PasteEvent                   // bracketed paste, see Event.Text.
+	SignalEvent                  // a (non resize) signal was received, see Event.Signal.
+)
+
+// Event is an input event, shared by the line mode [Terminal] and the raw mode AnsiPixels so
+// input handling layers (e.g. key bindings) can work with both. Use [DecodeEvents] to get
+// events from raw input bytes.
+type Event struct {
+	Type EventType
+	// Key pressed for KeyEvent: the rune or, for special keys, one of the Key* constants.
+	Key rune
+	// Mouse position (1 based cell coordinates) for MouseEvent, width and height for ResizeEvent.
+	X, Y int
+	// Mouse buttons and modifiers (same bits as the X10/SGR mouse protocols, release is 0b11).
+	Buttons int
+	// Pasted text for PasteEvent.
+	Text string
+	// Signal for SignalEvent.
+	Signal os.Signal
+}
+
+var (
+	mouseX10Prefix = []byte("\033[M")
+	mouseSGRPrefix = []byte("\033[<")
+	pasteStart     = []byte("\033[200~")
+	pasteEnd       = []byte("\033[201~")
+)
+
+// DecodeEvents decodes keys (including special keys like arrows), mouse events (X10 and SGR
+// formats) and bracketed paste from data. An incomplete sequence at the end of data is returned
+// as rest, to be prepended to the next data read.
+func DecodeEvents(data []byte) (events []Event, rest []byte) {
+	for len(data) > 0 {
+		ev, size := decodeEvent(data)
+		if size == 0 {
+			return events, data
+		}
+		events = append(events, ev)
+		data = data[size:]
+	}
+	return events, nil
+}
+
+// Returns the first event in data and its size, 0 if it's incomplete.
+func decodeEvent(data []byte) (Event, int) {
+	switch {
+	case bytes.HasPrefix(data, mouseX10Prefix):
+		if len(data) < 6 {
+			return Event{}, 0
+		}
+		return Event{Type: MouseEvent, Buttons: int(data[3]) - 32, X: int(data[4]) - 32, Y: int(data[5]) - 32}, 6
+	case bytes.HasPrefix(data, mouseSGRPrefix):
+		return decodeSGRMouse(data)
+	case bytes.HasPrefix(data, pasteStart):
+		end := bytes.Index(data, pasteEnd)
+		if end == -1 {
+			return Event{}, 0
+		}
+		return Event{Type: PasteEvent, Text: string(data[len(pasteStart):end])}, end + len(pasteEnd)
+	case len(data) >= 2 && data[0] == KeyEscape && data[1] == '[':
+		// CSI sequences are complete once we get the final byte (0x40 to 0x7e).
+		if bytes.IndexFunc(data[2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e }) == -1 {
+			return Event{}, 0
+		}
+	}
+	key, size := decodeKey(data)
+	return Event{Type: KeyEvent, Key: key}, size
+}
+
+// SGR (1006) mouse format: ESC[<b;x;yM for press (and motion), ending with m for release.
+func decodeSGRMouse(data []byte) (Event, int) {
+	end := bytes.IndexAny(data, "Mm")
+	if end == -1 {
+		return Event{}, 0
+	}
+	ev := Event{Type: MouseEvent}
+	fields := bytes.Split(data[len(mouseSGRPrefix):end], []byte{';'})
+	if len(fields) != 3 {
+		return Event{Type: KeyEvent, Key: KeyUnknown}, end + 1
+	}
+	vals := [3]*int{&ev.Buttons, &ev.X, &ev.Y}
+	for i, f := range fields {
+		v, err := strconv.Atoi(string(f))
+		if err != nil {
+			return Event{Type: KeyEvent, Key: KeyUnknown}, end + 1
+		}
+		*vals[i] = v
+	}
+	if data[end] == 'm' {
+		ev.Buttons |= 0b11 // release, like in X10 mode.
+	}
+	return ev, end + 1
+}
+
+// ReadEvent reads the next input event: a key (like [ReadKey]), a mouse event (if mouse
+// reporting was turned on) or a paste. Like ReadKey, Control-D returns io.EOF and Control-C or
+// a signal return ErrInterrupted. Extra input is kept for the next ReadEvent, ReadKey or ReadLine.
+func (t *Terminal) ReadEvent() (Event, error) {
+	t.unreadPending()
+	buf := make([]byte, 256)
+	var pending []byte
+	for {
+		n, err := t.intrReader.Read(buf)
+		if err != nil {
+			t.intrReader.unread(pending)
+			return Event{}, err
+		}
+		pending = append(pending, buf[:n]...)
+		ev, size := decodeEvent(pending)
+		if size == 0 {
+			continue // incomplete, read more.
+		}
+		t.intrReader.unread(pending[size:])
+		if ev.Type == KeyEvent && ev.Key == CtrlD {
+			return ev, io.EOF
+		}
+		return ev, nil
+	}
+}
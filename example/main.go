@@ -26,16 +26,19 @@ func main() {
 
 const (
 	promptCmd = "prompt "
+	statusCmd = "status " // set the status line below the prompt
+	fillCmd   = "fill "   // pre-fill the next line with the rest
 	afterCmd  = "after "
 	sleepCmd  = "sleep "
 	cancelCmd = "cancel " // simulate an external interrupt
 	runCmd    = "run "    // run a command after suspending the terminal
 	exitCmd   = "exit"
 	helpCmd   = "help"
+	passwdCmd = "password" // demo of ReadPassword
 	testMLCmd = "multiline"
 )
 
-var commands = []string{promptCmd, afterCmd, sleepCmd, cancelCmd, exitCmd, helpCmd, testMLCmd}
+var commands = []string{promptCmd, statusCmd, fillCmd, afterCmd, sleepCmd, cancelCmd, exitCmd, helpCmd, passwdCmd, testMLCmd}
 
 // func(line string, pos int, key rune) (newLine string, newPos int, ok bool)
 
@@ -81,17 +84,62 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 	flagHistory := flag.String("history", ".history", "History `file` to use")
 	flagMaxHistory := flag.Int("max-history", 10, "Max number of history lines to keep")
 	flagOnlyValid := flag.Bool("only-valid", false, "Demonstrates filtering of history, only adding valid commands to it")
+	flagNoPaste := flag.Bool("no-bracketed-paste", false, "Turn off bracketed paste mode, e.g. when pasting from a script")
+	flagPasteEdit := flag.Bool("paste-edit", false, "Pasted lines are only executed (together) after pressing Enter")
+	flagSpinner := flag.Bool("spinner", false, "Show a spinner in the status line while waiting for input")
+	flagVi := flag.Bool("vi", false, "Use vi style editing (Esc for normal mode)")
+	flagSuggest := flag.Bool("suggest", false, "Show history based suggestions while typing (accept with right arrow or ^E)")
+	flagMultiline := flag.Bool("multiline", false, "Keep reading lines until { and } are balanced")
+	flagRPrompt := flag.String("rprompt", "", "Right prompt to show on the prompt line")
+	flagClock := flag.Bool("clock", false, "Show a live (updated every second) clock in the prompt")
 	cli.Main()
 	t, err := terminal.Open(context.Background())
 	if err != nil {
 		return log.FErrf("Error opening terminal: %v", err)
 	}
 	defer t.Close()
+	defer t.RestoreOnPanic()
+	if *flagNoPaste {
+		t.SetBracketedPaste(false)
+	}
+	if *flagPasteEdit {
+		t.SetPastePolicy(terminal.PasteEditFirst)
+	}
+	if *flagVi {
+		t.SetEditMode(terminal.ViMode)
+	}
+	if *flagSuggest {
+		t.SetAutoSuggest(true)
+	}
+	if *flagMultiline {
+		t.SetMultiline(true, func(text string) bool {
+			return strings.Count(text, "{") <= strings.Count(text, "}")
+		})
+	}
+	if *flagSpinner {
+		spinner := []rune(`|/-\`)
+		i := 0
+		t.SetIdleTick(250*time.Millisecond, func(t *terminal.Terminal) {
+			t.SetStatusLine(string(spinner[i%len(spinner)]) + " waiting for input")
+			i++
+		})
+	}
 	onlyValid := *flagOnlyValid
 	if onlyValid {
 		t.SetAutoHistory(false)
 	}
 	t.SetPrompt("Terminal demo> ")
+	t.SetRightPrompt(*flagRPrompt)
+	if *flagClock {
+		t.SetPromptFunc(func() string {
+			return time.Now().Format("15:04:05") + " demo> "
+		})
+		go func() {
+			for range time.Tick(time.Second) {
+				t.RefreshPrompt()
+			}
+		}()
+	}
 	t.NewHistory(*flagMaxHistory)
 	if err = t.SetHistoryFile(*flagHistory); err != nil {
 		// error already logged
@@ -105,7 +153,7 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 	previousCommandWasValid := true // won't be used because `line` is empty at start
 	isValidCommand := true
 	var cmd string
-	interrupts := 0
+	t.SetExitOnInterrupts(3)
 	ctx := t.Context
 	cancel := t.Cancel
 	var terr terminal.InterruptedError
@@ -116,17 +164,15 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 		switch {
 		case err == nil:
 			// no error is good, nothing in this switch.
+		case errors.Is(err, terminal.ErrExitRequested):
+			log.Infof("Triple interrupt, exiting.")
+			return 0
 		case errors.Is(err, io.EOF):
 			log.Infof("EOF received, exiting.")
 			return 0
 		case errors.As(err, &terr):
-			interrupts++
-			if interrupts >= 3 {
-				log.Infof("Triple %v, exiting.", terr)
-				return 0
-			}
-			log.Infof("Interrupted (%d, %v), resetting, use exit or ^D. to exit.", interrupts, terr)
-			ctx, cancel = t.ResetInterrupts(context.Background()) //nolint:fatcontext // this is only upon interrupt.
+			log.Infof("Interrupted (%v), resetting, use exit or ^D. to exit.", terr)
+			ctx, cancel = t.Context, t.Cancel // reset by ReadLine (see SetExitOnInterrupts).
 		default:
 			return log.FErrf("Error reading line: %v", err)
 		}
@@ -141,8 +187,26 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 		log.Infof("Read line got: %q", cmd)
 		switch {
 		case cmd == exitCmd:
-			log.Infof("Exit command received, exiting.")
-			return 0
+			// Demonstrates single key prompts.
+			fmt.Fprintf(t.Out, "Really exit (y/n)? ")
+			k, kerr := t.ReadKey()
+			fmt.Fprintf(t.Out, "%q\n", k)
+			if kerr != nil || k == 'y' || k == 'Y' {
+				log.Infof("Exit command received, exiting.")
+				return 0
+			}
+			isValidCommand = true
+		case cmd == passwdCmd:
+			pw, perr := t.ReadPassword("Password: ")
+			if perr != nil {
+				log.Infof("Password not read: %v", perr)
+				if errors.As(perr, &terr) {
+					ctx, cancel = t.ResetInterrupts(context.Background()) //nolint:fatcontext // this is only upon interrupt.
+				}
+				continue
+			}
+			fmt.Fprintf(t.Out, "Got a %d characters password\n", len([]rune(pw)))
+			isValidCommand = true
 		case cmd == helpCmd:
 			fmt.Fprintf(t.Out, "Available commands: %v\n", commands)
 			isValidCommand = true
@@ -156,7 +220,6 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 			err = terminal.SleepWithContext(t.Context, dur)
 			if err != nil {
 				log.Infof("Sleep interrupted: %v", err)
-				interrupts = 0
 			}
 		case strings.HasPrefix(cmd, cancelCmd):
 			dur, _, ok := parseWithDur(t, cmd, 2, cancelCmd+"<duration>")
@@ -165,7 +228,6 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 			}
 			isValidCommand = true
 			log.Infof("Will generate cancel() after %v", dur)
-			interrupts = 0
 			go func(ctx context.Context, cancel context.CancelFunc) {
 				time.Sleep(dur)
 				if ctx.Err() != nil {
@@ -184,7 +246,7 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 			log.Infof("Will show %q after %v", rest, dur)
 			go func() {
 				time.Sleep(dur)
-				fmt.Fprintf(t.Out, "%s\n", rest)
+				t.Printf("%s", rest)
 			}()
 		case strings.HasPrefix(cmd, promptCmd):
 			if onlyValid {
@@ -192,6 +254,15 @@ func Main() int { //nolint:funlen // long but simple (and some amount of copy pa
 			}
 			t.SetPrompt(cmd[len(promptCmd):])
 			isValidCommand = true
+		case strings.HasPrefix(cmd, statusCmd):
+			if onlyValid {
+				t.AddToHistory(cmd)
+			}
+			t.SetStatusLine(cmd[len(statusCmd):])
+			isValidCommand = true
+		case strings.HasPrefix(cmd, fillCmd):
+			t.SetLine(cmd[len(fillCmd):], 0)
+			isValidCommand = true
 		case strings.HasPrefix(cmd, runCmd):
 			if onlyValid {
 				t.AddToHistory(cmd)
@@ -1,12 +1,13 @@
 package main
 
 import (
-	"bytes"
-	_ "embed"
+	"embed"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"image/color"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -144,11 +145,8 @@ func animate(ap *ansipixels.AnsiPixels, frame int64) {
 	charAt(ap, pos-1, w, h, ansipixels.ResetClear) // erase and reset color
 }
 
-//go:embed fps.jpg
-var fpsJpg []byte
-
-//go:embed fps_colors.jpg
-var fpsColorsJpg []byte
+//go:embed fps.jpg fps_colors.jpg
+var assets embed.FS
 
 func mouseOffets(ap *ansipixels.AnsiPixels, offsetX, offsetY *int) {
 	dx := float64(ap.Mx - ap.W/2)
@@ -157,6 +155,28 @@ func mouseOffets(ap *ansipixels.AnsiPixels, offsetX, offsetY *int) {
 	*offsetY -= safecast.MustRound[int](dy)
 }
 
+// Help overlay, scrollable (arrows, page up/down) if the screen is too small, any other key closes it.
+func showHelp(ap *ansipixels.AnsiPixels, text string) error {
+	tv := ansipixels.NewTextView(text)
+	for {
+		w := min(ap.W-4, 80)
+		h := min(ap.H-4, 10)
+		x, y := (ap.W-w)/2, (ap.H-h)/2
+		ap.DrawRoundBox(x-1, y-1, w+2, h+2)
+		tv.Draw(ap, x, y, w, h)
+		ap.Out.Flush()
+		if err := ap.ReadOrResizeOrSignal(); err != nil {
+			return err
+		}
+		if len(ap.Data) == 0 { // resize or mouse event
+			continue
+		}
+		if !tv.HandleKey(ap.Data) {
+			return nil
+		}
+	}
+}
+
 func imagesViewer(ap *ansipixels.AnsiPixels, imageFiles []string) int { //nolint:funlen,gocyclo // yeah well...
 	ap.Data = make([]byte, 3)
 	i := 0
@@ -167,6 +187,7 @@ func imagesViewer(ap *ansipixels.AnsiPixels, imageFiles []string) int { //nolint
 		zoom := 1.0
 		offsetX := 0
 		offsetY := 0
+		fitWidth := false
 		if i >= l {
 			i = 0
 		}
@@ -183,12 +204,8 @@ func imagesViewer(ap *ansipixels.AnsiPixels, imageFiles []string) int { //nolint
 		ap.OnResize = func() error {
 			ap.StartSyncMode()
 			ap.ClearScreen()
-			/* todo: prevent image from going off screen
-			offsetX = max(-ap.W+1, offsetX)
-			offsetX = min(2*ap.W-1, offsetX)
-			offsetY = max(-ap.H+1, offsetY)
-			offsetY = min(2*ap.H-1, offsetY)
-			*/
+			// prevent image from going off screen (and panning further having no visible effect).
+			offsetX, offsetY = ap.ClampOffsets(img, zoom, offsetX, offsetY)
 			e := ap.ShowImage(img, zoom, offsetX, offsetY, defaultMonoImageColor)
 			if showInfo {
 				ap.WriteRight(ap.H-1, "%s", info)
@@ -228,11 +245,18 @@ func imagesViewer(ap *ansipixels.AnsiPixels, imageFiles []string) int { //nolint
 		justRedraw := true
 		switch c {
 		case '?', 'h', 'H':
-			ap.WriteCentered(ap.H/2-1, "Showing %d out of %d images, hit any key to continue, up/down for zoom,", i+1, l)
-			ap.WriteCentered(ap.H/2, "WSAD to pan, 'q' to exit, left arrow to go back, 'i' to toggle image information")
-			ap.WriteCentered(ap.H/2+1, "or Mouse wheel to zoom, Mouse click center; 'c' to reset to center of the image.")
-			ap.Out.Flush()
-			goto wait
+			err = showHelp(ap, fmt.Sprintf("Showing %d out of %d images, up/down for zoom, "+
+				"WSAD to pan, 'q' to exit, left arrow to go back, 'i' to toggle image information "+
+				"or Mouse wheel to zoom, Mouse click center; 'c' to reset to center of the image, "+
+				"'r' to rotate 90°, 'f' to toggle fit to width/height, '1' for actual size (100%%), "+
+				"'g' for the thumbnails grid (arrows to select, Enter to open), 'p' to copy the color under the mouse.\n\n"+
+				"Any other key to continue.", i+1, l))
+			if errors.Is(err, terminal.ErrSignal) {
+				return 0
+			}
+			if err != nil {
+				return log.FErrf("Error reading key: %v", err)
+			}
 		case 'i', 'I':
 			changedInfo = true
 			showInfo = !showInfo
@@ -255,7 +279,40 @@ func imagesViewer(ap *ansipixels.AnsiPixels, imageFiles []string) int { //nolint
 		case 'c', 'C':
 			offsetX = 0
 			offsetY = 0
-		case 12: // ^L, refresh
+		case 'r', 'R':
+			img.Rotate90()
+			info = fmt.Sprintf("%s (%dx%d %s%s)", imageFile, img.Width, img.Height, img.Format, extra)
+			offsetX = 0
+			offsetY = 0
+		case 'f', 'F':
+			fitWidth = !fitWidth
+			zoom = ap.FitZoom(img, fitWidth)
+			offsetX = 0
+			offsetY = 0
+		case '1':
+			zoom = ap.ActualSizeZoom(img)
+		case 'p', 'P':
+			// eyedropper: copy the color under the mouse (last mouse event position, 1 based), the
+			// center if there was none yet.
+			x, y := ap.W/2, ap.H/2
+			if ap.Mx > 0 && ap.My > 0 {
+				x, y = ap.Mx-1, ap.My-1
+			}
+			px := ap.ImageColorAt(img, zoom, offsetX, offsetY, x, y)
+			hex := fmt.Sprintf("#%02x%02x%02x", px.R, px.G, px.B)
+			ap.CopyToClipboard(hex)
+			ap.WriteRight(ap.H-1, "\033[38;2;%d;%d;%dm█%s %s copied to clipboard", px.R, px.G, px.B, ansipixels.Reset, hex)
+			ap.Out.Flush()
+			goto wait
+		case 'g', 'G':
+			i, err = thumbnailsGrid(ap, imageFiles, i)
+			if errors.Is(err, terminal.ErrSignal) || (err == nil && i < 0) {
+				return 0
+			}
+			if err != nil {
+				return log.FErrf("Error in thumbnails grid: %v", err)
+			}
+			continue
 		default:
 			justRedraw = false
 		}
@@ -314,12 +371,18 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 	trueColorFlag := flag.Bool("truecolor", defaultTrueColor,
 		"If your terminal supports truecolor, this will load image in truecolor (24bits) instead of monochrome")
 	grayFlag := flag.Bool("gray", false, "Convert the image to grayscale")
+	sixelFlag := flag.Bool("sixel", ansipixels.DetectSixel(),
+		"If your terminal supports Sixel graphics, this will show images at full resolution using them")
+	kittyFlag := flag.Bool("kitty", ansipixels.DetectKittyGraphics(),
+		"If your terminal supports the kitty graphics protocol, this will show images at full resolution using it")
 	noboxFlag := flag.Bool("nobox", false,
 		"Don't draw the box around the image, make the image full screen instead of 1 pixel less on all sides")
 	imagesOnlyFlag := flag.Bool("i", false, "Arguments are now images files to show, no FPS test (hit any key to continue)")
 	exactlyFlag := flag.Int64("n", 0, "Start immediately an FPS test with the specified `number of frames` (default is interactive)")
 	noMouseFlag := flag.Bool("nomouse", false, "Disable mouse tracking")
 	fireFlag := flag.Bool("fire", false, "Show fire animation instead of RGB around the image")
+	letterbox := ansipixels.ColorFlag{A: 255}
+	flag.Var(&letterbox, "letterbox", "`Color` of the bars around images not filling the screen, e.g. #333 or rgb(40 40 40)")
 	cli.MinArgs = 0
 	cli.MaxArgs = -1
 	cli.ArgsHelp = "[maxfps] or fps -i imagefiles..."
@@ -354,9 +417,19 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 	if err := ap.Open(); err != nil {
 		log.Fatalf("Not a terminal: %v", err)
 	}
+	ap.RedrawKey = 12 // ^L
 	ap.TrueColor = *trueColorFlag
 	ap.Color = *colorFlag
 	ap.Gray = *grayFlag
+	ap.Sixel = *sixelFlag
+	ap.KittyGraphics = *kittyFlag
+	ap.LetterboxColor = color.RGBA(letterbox)
+	if ap.Sixel || ap.KittyGraphics {
+		// Once, here, instead of in the middle of drawing an image.
+		if _, _, err := ap.ReadCellSize(); err != nil {
+			log.Warnf("Unable to get the cell size, images may be distorted: %v", err)
+		}
+	}
 	ap.Margin = 1
 	if *noboxFlag || imagesOnly {
 		ap.Margin = 0
@@ -366,6 +439,10 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 		ap.MouseTrackingOff()
 		ap.MouseClickOff()
 		ap.Restore() // flushes and shows cursor and resets terminal back to original state.
+		if h := ap.FrameHistogram(); h != nil && h.Count > 0 {
+			p := h.Export().CalcPercentiles([]float64{50, 99})
+			log.Infof("Frame time p50 %.2f ms, p99 %.2f ms (%d frames)", p.Percentiles[0].Value, p.Percentiles[1].Value, h.Count)
+		}
 	}()
 	// GetSize done in Open (and resize signal handler).
 	ap.HideCursor()
@@ -380,9 +457,9 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 	var err error
 	if *imgFlag == "" {
 		if *trueColorFlag || *colorFlag {
-			background, err = ap.DecodeImage(bytes.NewReader(fpsColorsJpg))
+			background, err = ap.ReadImageFS(assets, "fps_colors.jpg")
 		} else {
-			background, err = ap.DecodeImage(bytes.NewReader(fpsJpg))
+			background, err = ap.ReadImageFS(assets, "fps.jpg")
 		}
 	} else {
 		background, err = ap.ReadImage(*imgFlag)
@@ -444,6 +521,7 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 		ap.EndSyncMode()
 		// with max fps expect values in the tens of usec range with usec precision (at max fps for fast terminals)
 		perfResults.hist = stats.NewHistogram(0, 0.0000001)
+		ap.ResetFrameStats()
 		frames = 0
 		setLabels("fps "+strings.TrimSuffix(fpsStr, ".0"), tenv, fmt.Sprintf("%dx%d", ap.W, ap.H), fireStr)
 		hideText = false
@@ -488,17 +566,20 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 			if frames > 0 {
 				perfResults.hist.Record(sec) // record in milliseconds
 			}
+			ap.RecordFrame() // same data in ap.FrameHistogram(), logged on exit.
 			if fireMode {
 				ap.StartSyncMode()
 				AnimateFire(ap, frames)
 			}
 			// stats.Record("fps", fps)
 			if !hideText {
-				ap.WriteAt(ap.W/2-20, ap.H/2+2, "%s Last frame %s%v%s FPS: %s%.0f%s Avg %s%.2f%s ",
+				cx, cy := ap.Pct(0.5, 0.5)
+				cx = max(0, cx-20)
+				ap.WriteAt(cx, cy+2, "%s Last frame %s%v%s FPS: %s%.0f%s Avg %s%.2f%s ",
 					ansipixels.Reset, ansipixels.Green, elapsed.Round(10*time.Microsecond), ansipixels.Reset,
 					ansipixels.BrightRed, fps, ansipixels.Reset,
 					ansipixels.Cyan, perfResults.ActualQPS, ansipixels.Reset)
-				ap.WriteAt(ap.W/2-20, ap.H/2+3, " Best %.1f Worst %.1f: %.1f +/- %.1f ",
+				ap.WriteAt(cx, cy+3, " Best %.1f Worst %.1f: %.1f +/- %.1f ",
 					1/perfResults.hist.Min, 1/perfResults.hist.Max, 1/perfResults.hist.Avg(), 1/perfResults.hist.StdDev())
 			}
 			if perfResults.Exactly > 0 && frames >= perfResults.Exactly {
@@ -518,7 +599,15 @@ func Main() int { //nolint:funlen,gocognit,gocyclo,maintidx // color and mode if
 					invert, ap.Mx, ap.My, ap.Mbuttons, ansipixels.Reset)
 			}
 			// Request cursor position (note that FPS is about the same without it, the Flush seems to be enough)
-			_, _, err = ap.ReadCursorPos()
+			if ap.SnapshotMode() {
+				// Nothing to synchronize with, just get the keys (if any, stdin may not be a terminal either).
+				ap.EndSyncMode()
+				if _, err = ap.ReadOrResizeOrSignalOnce(); errors.Is(err, io.EOF) {
+					err = nil
+				}
+			} else {
+				_, _, err = ap.ReadCursorPos()
+			}
 			if err != nil {
 				return log.FErrf("Error with cursor position request: %v", err)
 			}
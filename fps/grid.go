@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fortio.org/log"
+	"fortio.org/terminal/ansipixels"
+)
+
+const (
+	thumbW = 24 // thumbnail cell width including the border.
+	thumbH = 12 // thumbnail cell height including the border and the file name caption.
+)
+
+// Contact sheet mode: shows the images as a grid of thumbnails, arrow keys (or mouse click) to
+// move the selection, Enter to open the selected one full size. Returns the index of the image
+// to show or -1 to exit.
+func thumbnailsGrid(ap *ansipixels.AnsiPixels, imageFiles []string, selected int) (int, error) {
+	l := len(imageFiles)
+	cache := make(map[int]*ansipixels.Image) // only the current page is kept.
+	page := -1
+	var cols, rows int
+	ap.OnResize = func() error {
+		cols = max(1, ap.W/thumbW)
+		rows = max(1, (ap.H-1)/thumbH)
+		perPage := cols * rows
+		if p := selected / perPage; p != page {
+			page = p
+			clear(cache)
+		}
+		ap.StartSyncMode()
+		ap.ClearScreen()
+		for n := range perPage {
+			idx := page*perPage + n
+			if idx >= l {
+				break
+			}
+			x := (n % cols) * thumbW
+			y := (n / cols) * thumbH
+			img, ok := cache[idx]
+			if !ok {
+				var err error
+				img, err = ap.ReadImage(imageFiles[idx])
+				if err != nil {
+					log.Errf("Error reading image %s: %v", imageFiles[idx], err)
+				}
+				cache[idx] = img // nil on error so we don't retry.
+			}
+			if img != nil {
+				if err := ap.DrawImageInRect(img, x+1, y+1, thumbW-2, thumbH-3, defaultMonoImageColor); err != nil {
+					return err
+				}
+			} else {
+				ap.WriteAtStr(x+1, y+1, "❌ error")
+			}
+			caption, _ := ap.TruncateLeftToFit(imageFiles[idx], thumbW-2)
+			ap.WriteAtStr(x+1, y+thumbH-2, caption)
+			if idx == selected {
+				ap.DrawRoundBoxC(x, y, thumbW, thumbH, ansipixels.BrightCyan)
+			}
+		}
+		ap.WriteRight(ap.H-1, "%d/%d - arrows to select, Enter to open, q to exit", selected+1, l)
+		ap.EndSyncMode()
+		return nil
+	}
+	for {
+		if err := ap.OnResize(); err != nil {
+			return -1, err
+		}
+		if err := ap.ReadOrResizeOrSignal(); err != nil {
+			return -1, err
+		}
+		if ap.LeftClick() {
+			n := (ap.My-1)/thumbH*cols + (ap.Mx-1)/thumbW
+			if idx := page*cols*rows + n; n < cols*rows && idx < l {
+				selected = idx
+			}
+			continue
+		}
+		if len(ap.Data) == 0 {
+			continue
+		}
+		if isStopKey(ap) {
+			return -1, nil
+		}
+		switch ap.Data[0] {
+		case '\r', '\n', 'g', 'G':
+			return selected, nil
+		}
+		if len(ap.Data) >= 3 && ap.Data[0] == 27 && ap.Data[1] == '[' {
+			switch ap.Data[2] {
+			case 'A': // up arrow
+				selected -= cols
+			case 'B': // down arrow
+				selected += cols
+			case 'C': // right arrow
+				selected++
+			case 'D': // left arrow
+				selected--
+			}
+			selected = min(max(selected, 0), l-1)
+		}
+		log.Debugf("Grid selected %d/%d", selected+1, l)
+	}
+}
@@ -0,0 +1,121 @@
+package terminal
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryAll(t *testing.T) {
+	tt, _ := newPipeTerminal(t, io.Discard)
+	tt.NewHistory(4)
+	check := func(expected []string) {
+		t.Helper()
+		var got []string
+		for h := range tt.HistoryAll() {
+			got = append(got, h)
+		}
+		if !slices.Equal(got, expected) || !slices.Equal(got, tt.History()) {
+			t.Errorf("got %q, expected %q (History() %q)", got, expected, tt.History())
+		}
+	}
+	tt.AddToHistory("a", "b")
+	check([]string{"b", "a"}) // partially filled.
+	tt.AddToHistory("c", "d", "e", "f")
+	check([]string{"f", "e", "d", "c"}) // wrapped around.
+	for h := range tt.HistoryAll() {
+		if h != "f" {
+			t.Errorf("expected to stop after the first entry, got %q", h)
+		}
+		break
+	}
+}
+
+func TestHistoryDedup(t *testing.T) {
+	tt, w := newPipeTerminal(t, io.Discard)
+	tt.NewHistory(4)
+	tt.SetHistoryDedup(HistoryDedupGlobal)
+	tt.AddToHistory("a", "b", "a", "b", "c", "a", "d")
+	if got, expected := tt.History(), []string{"d", "a", "c", "b"}; !slices.Equal(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+	tt.AddToHistory("e", "c") // wraps around, "b" falls off.
+	if got, expected := tt.History(), []string{"c", "e", "d", "a"}; !slices.Equal(got, expected) {
+		t.Errorf("after wrap around got %q, expected %q", got, expected)
+	}
+	tt.ReplaceLatest("d")
+	if got, expected := tt.History(), []string{"d", "e", "a"}; !slices.Equal(got, expected) {
+		t.Errorf("after replace got %q, expected %q", got, expected)
+	}
+	tt.SetAutoHistory(true)
+	_, _ = w.WriteString("a\r")
+	if l, err := tt.ReadLine(); err != nil || l != "a" {
+		t.Fatalf("unexpected ReadLine %q, %v", l, err)
+	}
+	if got, expected := tt.History(), []string{"a", "d", "e"}; !slices.Equal(got, expected) {
+		t.Errorf("after ReadLine got %q, expected %q", got, expected)
+	}
+	tt.NewHistory(4)
+	tt.SetHistoryDedup(HistoryDedupAdjacent)
+	n := tt.LoadHistory([]string{"x", "x", "y", "x", "z", "z", "w"})
+	if got, expected := tt.History(), []string{"w", "z", "x", "y"}; n != 4 || !slices.Equal(got, expected) {
+		t.Errorf("load got %d %q, expected %q", n, got, expected)
+	}
+	tt.SetHistoryDedup(HistoryDedupGlobal)
+	if got, expected := tt.History(), []string{"w", "z", "x", "y"}; !slices.Equal(got, expected) {
+		t.Errorf("global dedup of unique entries got %q, expected %q", got, expected)
+	}
+}
+
+func TestHistoryTimes(t *testing.T) {
+	tt, _ := newPipeTerminal(t, io.Discard)
+	tt.NewHistory(3)
+	tt.SetHistoryTimestamps(true)
+	t1, t2 := time.Unix(1000, 0), time.Unix(2000, 0)
+	tt.addHistory("ls", t1)
+	tt.addHistory("pwd", t1)
+	tt.addHistory("ls", t2) // repeated command, own time.
+	for n, expected := range []time.Time{t2, t1, t1} {
+		if _, added, ok := tt.HistoryAtWithTime(n); !ok || !added.Equal(expected) {
+			t.Errorf("entry %d: got %v (%t), expected %v", n, added, ok, expected)
+		}
+	}
+	tt.AddToHistory("a", "b") // first "ls" and "pwd" fall off the ring.
+	if len(tt.hist) != 3 || len(tt.histCount) != 3 || tt.histCount["pwd"] != 0 {
+		t.Errorf("unexpected mirror %v, counts %v", tt.hist, tt.histCount)
+	}
+	if e, added, ok := tt.HistoryAtWithTime(2); !ok || e != "ls" || !added.Equal(t2) {
+		t.Errorf("got %q %v (%t), expected ls at %v", e, added, ok, t2)
+	}
+	// Size limit includes the timestamp lines.
+	tt.hist[1].added, tt.hist[2].added = t1, t1
+	tt.SetHistoryLimits(0, 2*len("# 1000\n\"a\"\n"))
+	var buf strings.Builder
+	if err := tt.SaveHistory(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "# 1000\n\"a\"\n# 1000\n\"b\"\n"; buf.String() != expected {
+		t.Errorf("got %q, expected %q", buf.String(), expected)
+	}
+	entries, err := readHistory(strings.NewReader(buf.String() + "\"c\"\n"))
+	if err != nil || len(entries) != 3 || !entries[1].added.Equal(t1) || !entries[2].added.IsZero() {
+		t.Errorf("unexpected read back %v, %v", entries, err)
+	}
+}
+
+func TestHistoryEntryLimit(t *testing.T) {
+	tt, w := newPipeTerminal(t, io.Discard)
+	tt.NewHistory(4)
+	tt.SetAutoHistory(true)
+	tt.SetHistoryLimits(5, 0)
+	tt.AddToHistory("short", "too long")
+	_, _ = w.WriteString("pasted blob\r")
+	if l, err := tt.ReadLine(); err != nil || l != "pasted blob" {
+		t.Fatalf("unexpected ReadLine %q, %v", l, err)
+	}
+	if got, expected := tt.History(), []string{"short"}; !slices.Equal(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+}
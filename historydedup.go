@@ -0,0 +1,50 @@
+package terminal
+
+import "slices"
+
+// HistoryDedup is the policy for duplicate history entries, see [SetHistoryDedup].
+type HistoryDedup int
+
+const (
+	// HistoryDedupNone keeps duplicates as added (the default).
+	HistoryDedupNone HistoryDedup = iota
+	// HistoryDedupAdjacent drops an entry identical to the previous one.
+	HistoryDedupAdjacent
+	// HistoryDedupGlobal removes all the older occurrences of an entry when it's added again,
+	// so each command is only in the history once, at its most recent position.
+	HistoryDedupGlobal
+)
+
+// SetHistoryDedup sets how duplicate history entries are handled, both for new entries and when
+// loading the history (e.g. from the [SetHistoryFile] file). The current history is deduplicated
+// right away. Note that an entry identical to the previous one is never added (by term), so
+// HistoryDedupAdjacent only makes a difference when loading.
+func (t *Terminal) SetHistoryDedup(mode HistoryDedup) {
+	t.histDedup = mode
+	if h := dedupEntries(t.hist, mode); len(h) != len(t.hist) {
+		t.setHistory(h)
+	}
+}
+
+// Returns entries (oldest first) without the duplicates according to mode.
+func dedupEntries(entries []histEntry, mode HistoryDedup) []histEntry {
+	switch mode {
+	case HistoryDedupAdjacent:
+		return slices.CompactFunc(slices.Clone(entries), func(a, b histEntry) bool {
+			return a.text == b.text
+		})
+	case HistoryDedupGlobal:
+		seen := make(map[string]bool, len(entries))
+		res := make([]histEntry, 0, len(entries))
+		for i := len(entries) - 1; i >= 0; i-- { // keeping the most recent occurrence.
+			if !seen[entries[i].text] {
+				seen[entries[i].text] = true
+				res = append(res, entries[i])
+			}
+		}
+		slices.Reverse(res)
+		return res
+	default:
+		return entries
+	}
+}
@@ -0,0 +1,97 @@
+package terminal
+
+import (
+	"slices"
+	"time"
+
+	"fortio.org/log"
+	"fortio.org/term"
+)
+
+// histEntry is an entry of the history mirror, see addHistory.
+type histEntry struct {
+	text  string
+	added time.Time // zero if unknown or SetHistoryTimestamps is off.
+}
+
+// Returns the capacity of term's history ring.
+func (t *Terminal) histCapacity() int {
+	if t.capacity > 0 {
+		return t.capacity
+	}
+	return term.DefaultHistoryEntries
+}
+
+// Adds entry to the history: to term's ring (whose own adding in ReadLine is off) and to its
+// mirror, t.hist, which, unlike the ring, can be searched and modified, e.g. for the dedup policy,
+// and has the time each entry was added.
+func (t *Terminal) addHistory(entry string, added time.Time) {
+	if t.maxEntryLen > 0 && len(entry) > t.maxEntryLen {
+		log.LogVf("Not adding %d bytes entry to the history (limit %d)", len(entry), t.maxEntryLen)
+		return
+	}
+	if !t.histTimes {
+		added = time.Time{}
+	}
+	if n := len(t.hist); (n == 0 && entry == "") || (n > 0 && t.hist[n-1].text == entry) {
+		return // term's ring doesn't add an entry identical to the latest either.
+	}
+	if t.histDedup == HistoryDedupGlobal && t.histCount[entry] > 0 {
+		// The ring can't remove the older copy, so it's rebuilt, only in that case.
+		h := slices.DeleteFunc(t.hist, func(e histEntry) bool { return e.text == entry })
+		t.setHistory(append(h, histEntry{text: entry, added: added}))
+		return
+	}
+	if t.histCount == nil {
+		t.histCount = make(map[string]int)
+	}
+	t.term.AddToHistory(entry)
+	t.hist = append(t.hist, histEntry{text: entry, added: added})
+	t.histCount[entry]++
+	if len(t.hist) > t.histCapacity() { // fell off the ring.
+		t.forgetHistory(t.hist[0].text)
+		t.hist[0] = histEntry{}
+		t.hist = t.hist[1:]
+	}
+}
+
+// Updates the mirror after term's ReplaceLatest.
+func (t *Terminal) replaceLatestHistory(entry string, added time.Time) {
+	n := len(t.hist)
+	if n == 0 {
+		return
+	}
+	if !t.histTimes {
+		added = time.Time{}
+	}
+	t.forgetHistory(t.hist[n-1].text)
+	t.hist[n-1] = histEntry{text: entry, added: added}
+	t.histCount[entry]++
+	if t.histDedup == HistoryDedupGlobal && t.histCount[entry] > 1 {
+		h := slices.DeleteFunc(t.hist[:n-1], func(e histEntry) bool { return e.text == entry })
+		t.setHistory(append(h, t.hist[n-1]))
+	}
+}
+
+// Replaces the history (ring and mirror) with h, oldest first.
+func (t *Terminal) setHistory(h []histEntry) {
+	if extra := len(h) - t.histCapacity(); extra > 0 {
+		h = h[extra:]
+	}
+	t.hist = h
+	t.histCount = make(map[string]int, len(h))
+	entries := make([]string, len(h))
+	for i, e := range h {
+		entries[i] = e.text
+		t.histCount[e.text]++
+	}
+	t.term.NewHistory(t.histCapacity())
+	t.term.AddToHistory(entries...)
+}
+
+// Updates the occurrences count after entry was removed from the mirror.
+func (t *Terminal) forgetHistory(entry string) {
+	if t.histCount[entry]--; t.histCount[entry] <= 0 {
+		delete(t.histCount, entry)
+	}
+}
@@ -0,0 +1,30 @@
+package terminal
+
+import "time"
+
+// Prefix of the (zsh like) comment line with the unix time of the next entry in the history file.
+const historyTimePrefix = "# "
+
+// SetHistoryTimestamps turns on (or off) recording when history entries are added and saving
+// that time in the history file, as a "# <unix time>" line before each entry. Files with or
+// without timestamps can be loaded either way, but times are only kept when this is on, so it
+// should be called before [SetHistoryFile].
+func (t *Terminal) SetHistoryTimestamps(enabled bool) {
+	t.histTimes = enabled
+	if enabled {
+		return
+	}
+	for i := range t.hist {
+		t.hist[i].added = time.Time{}
+	}
+}
+
+// HistoryAtWithTime returns the nth most recent history entry (0 is the latest) and when it was
+// added (zero time if unknown). ok is false if there is no such entry.
+func (t *Terminal) HistoryAtWithTime(n int) (entry string, added time.Time, ok bool) {
+	if n < 0 || n >= len(t.hist) {
+		return "", time.Time{}, false
+	}
+	e := t.hist[len(t.hist)-1-n]
+	return e.text, e.added, true
+}
@@ -23,6 +23,10 @@ type InterruptReader struct {
 	cond    sync.Cond
 	cancel  context.CancelFunc
 	stopped bool
+	seq     []byte        // interrupt sequence ([CtrlC] by default), empty when disabled.
+	within  time.Duration // max delay between the bytes of seq, 0 for no limit.
+	matched int           // number of bytes of seq matched so far.
+	last    time.Time     // time of the last matched byte.
 }
 
 var (
@@ -63,6 +67,7 @@ func NewInterruptReader(reader *os.File, bufSize int) *InterruptReader {
 		reader:  reader,
 		bufSize: bufSize,
 		buf:     make([]byte, 0, bufSize),
+		seq:     []byte{CtrlC},
 	}
 	ir.reset = ir.buf
 	ir.cond = *sync.NewCond(&ir.mu)
@@ -124,8 +129,82 @@ func (ir *InterruptReader) Read(p []byte) (int, error) {
 	return n, err
 }
 
+// Puts back b in front of the not yet read input.
+func (ir *InterruptReader) unread(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	ir.mu.Lock()
+	ir.buf = append(bytes.Clone(b), ir.buf...)
+	ir.cond.Signal()
+	ir.mu.Unlock()
+}
+
+// Adds b to the input, after what's already pending, as if it had been typed.
+func (ir *InterruptReader) inject(b []byte) {
+	ir.mu.Lock()
+	ir.buf = append(ir.buf, b...)
+	ir.cond.Signal()
+	ir.mu.Unlock()
+}
+
 const CtrlC = 3 // Control-C is ascii 3 (C is 3rd letter of the alphabet)
 
+// SetInterruptByte changes the byte interrupting the reading (and canceling the context) to b,
+// [CtrlC] by default, or, when enabled is false, disables it so that byte (e.g. Control-C) is
+// passed through like any other input (signals still interrupt).
+func (ir *InterruptReader) SetInterruptByte(b byte, enabled bool) {
+	if !enabled {
+		ir.SetInterruptSequence(nil, 0)
+		return
+	}
+	ir.SetInterruptSequence([]byte{b}, 0)
+}
+
+// SetInterruptSequence sets a multi bytes sequence that interrupts the reading, each byte having
+// to follow the previous one within the given delay (0 for no limit). For instance
+// SetInterruptSequence([]byte{CtrlC, CtrlC}, 500*time.Millisecond) lets the application handle
+// single Control-C itself while a quick double Control-C still force quits. The bytes before the
+// last one of the sequence are passed through as regular input. An empty seq disables interrupts
+// from the input (signals still interrupt).
+func (ir *InterruptReader) SetInterruptSequence(seq []byte, within time.Duration) {
+	ir.mu.Lock()
+	ir.seq = bytes.Clone(seq)
+	ir.within = within
+	ir.matched = 0
+	ir.mu.Unlock()
+}
+
+// Returns the index in buf of the byte completing the interrupt sequence, -1 if not found (or disabled).
+func (ir *InterruptReader) interruptIndex(buf []byte) int {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+	if len(ir.seq) == 0 {
+		return -1
+	}
+	now := time.Now()
+	for i, c := range buf {
+		if ir.matched > 0 && ir.within > 0 && now.Sub(ir.last) > ir.within {
+			ir.matched = 0
+		}
+		switch {
+		case c == ir.seq[ir.matched]:
+			ir.matched++
+		case c == ir.seq[0]:
+			ir.matched = 1
+		default:
+			ir.matched = 0
+			continue
+		}
+		ir.last = now
+		if ir.matched == len(ir.seq) {
+			ir.matched = 0
+			return i
+		}
+	}
+	return -1
+}
+
 func (ir *InterruptReader) start(ctx context.Context) {
 	localBuf := make([]byte, ir.bufSize)
 	sigc := make(chan os.Signal, 1)
@@ -159,9 +238,9 @@ func (ir *InterruptReader) start(ctx context.Context) {
 				continue
 			}
 			localBuf = localBuf[:n]
-			idx := bytes.IndexByte(localBuf, CtrlC)
+			idx := ir.interruptIndex(localBuf)
 			if idx != -1 {
-				log.Infof("Ctrl-C found in input")
+				log.Infof("Interrupt (%q) found in input", localBuf[idx])
 				localBuf = localBuf[:idx] // discard ^C and the rest.
 				ir.mu.Lock()
 				ir.cancel()
@@ -0,0 +1,92 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+)
+
+// KeySpec is a key as the bytes the terminal sends for it, e.g. "\x0c" for Control-L (see
+// [CtrlKey]) or "\033[A" for the up arrow.
+type KeySpec string
+
+// CtrlKey returns the KeySpec of Control-<letter>, e.g. CtrlKey('L').
+func CtrlKey(letter byte) KeySpec {
+	return KeySpec([]byte{letter&^0x20 - '@'})
+}
+
+// KeyAction is the function called when a bound key is pressed during ReadLine. Returning an error
+// ends the ReadLine with that error (e.g. io.EOF or ErrUserInterrupt).
+type KeyAction func(t *Terminal) error
+
+// DefaultKeyBindings are the bindings a Terminal starts with (changing it affects the
+// terminals opened afterwards). Keys not bound are handled by the line editor as usual.
+var DefaultKeyBindings = map[KeySpec]KeyAction{
+	CtrlKey('L'): ClearScreen,
+}
+
+// BindKey makes key run action during ReadLine, before (and instead of) the normal line
+// editor handling of that key. A nil action removes the binding. Pasted text never triggers
+// bindings.
+func (t *Terminal) BindKey(key KeySpec, action KeyAction) {
+	if t.bindings == nil {
+		t.bindings = make(map[KeySpec]KeyAction)
+	}
+	if action == nil {
+		delete(t.bindings, key)
+		return
+	}
+	t.bindings[key] = action
+}
+
+// Built-in key actions.
+
+// ClearScreen clears the screen, the prompt and line being edited are then redrawn at the top.
+func ClearScreen(t *Terminal) error {
+	_, err := io.WriteString(t.Out, "\033[H\033[2J")
+	return err
+}
+
+// Interrupt makes ReadLine return ErrUserInterrupt, like Control-C (but without canceling
+// t.Context).
+func Interrupt(_ *Terminal) error {
+	return ErrUserInterrupt
+}
+
+// EOF makes ReadLine return io.EOF, like Control-D on an empty line.
+func EOF(_ *Terminal) error {
+	return io.EOF
+}
+
+// HistoryPrev recalls the previous history entry, like the up arrow (which thus can't be bound
+// to it).
+func HistoryPrev(t *Terminal) error {
+	t.intrReader.unread([]byte("\033[A"))
+	return nil
+}
+
+// HistoryNext recalls the next history entry, like the down arrow (which thus can't be bound
+// to it).
+func HistoryNext(t *Terminal) error {
+	t.intrReader.unread([]byte("\033[B"))
+	return nil
+}
+
+// Returns the index in the input chunk of the first bound key, with its length and action (or
+// -1 if none). Pasted text is left as is and a bound Esc alone only matches an Esc alone (and not
+// the start of an escape sequence).
+func (t *Terminal) findBoundKey(in []byte) (idx, size int, action KeyAction) {
+	idx = -1
+	if len(t.bindings) == 0 || t.paste.pasting || bytes.Contains(in, pasteStart) {
+		return
+	}
+	for key, a := range t.bindings {
+		if key == "" || (key == "\033" && len(in) > 1) {
+			continue
+		}
+		i := bytes.Index(in, []byte(key))
+		if i >= 0 && (idx < 0 || i < idx || (i == idx && len(key) > size)) {
+			idx, size, action = i, len(key), a
+		}
+	}
+	return idx, size, action
+}
@@ -0,0 +1,32 @@
+package terminal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBindKey(t *testing.T) {
+	var out bytes.Buffer
+	tt, w := newPipeTerminal(t, &out)
+	tt.BindKey(CtrlKey('L'), ClearScreen)
+	tt.BindKey(CtrlKey('T'), EOF)
+	if _, err := w.WriteString("ab\x0cc\r"); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	line, err := tt.ReadLine()
+	if err != nil || line != "abc" {
+		t.Errorf("got %q (%v), expected \"abc\"", line, err)
+	}
+	if !strings.Contains(out.String(), "\033[H\033[2J") {
+		t.Errorf("clear screen sequence not found in output %q", out.String())
+	}
+	if _, err = w.WriteString("xyz\x14"); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if _, err = tt.ReadLine(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, expected EOF", err)
+	}
+}
@@ -0,0 +1,58 @@
+package terminal
+
+// keyFilter sits between the InterruptReader and the term editor to handle keys the editor
+// would otherwise process (or swallow) without calling autoComplete: Esc in ViMode, the
+// keys accepting an auto suggestion, the history search keys, the kill ring keys and the
+// arrows in multiline mode. It also runs the key bindings, ends ReadLineContext reads and
+// hides the right prompt when needed.
+type keyFilter struct {
+	t       *Terminal
+	pending []byte
+}
+
+func (kf *keyFilter) Read(buf []byte) (int, error) {
+	for len(kf.pending) == 0 {
+		if err := kf.t.abortError(); err != nil {
+			return 0, err
+		}
+		n, err := kf.t.intrReader.Read(buf)
+		if err != nil {
+			return n, err
+		}
+		if kf.t.password {
+			return len(kf.t.lineEndInput(buf[:n])), nil
+		}
+		in := kf.t.lineEndInput(kf.t.abortInput(buf[:n]))
+		if i, size, action := kf.t.findBoundKey(in); i > 0 {
+			kf.t.intrReader.unread(in[i:]) // the keys before the bound one are processed first.
+			in = in[:i]
+		} else if i == 0 {
+			kf.t.intrReader.unread(in[size:])
+			if err = action(kf.t); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		// Before trackPaste as they may unread part of the input.
+		if kf.t.search != nil {
+			in = kf.t.searchInput(in)
+		} else {
+			in = kf.t.searchStartInput(in)
+		}
+		pasted := kf.t.pastedPrefix(in)
+		kf.t.trackPaste(in)
+		kf.t.rightPromptInput(in)
+		if kf.t.search == nil {
+			in = kf.t.suggestionInput(kf.t.multilineInput(in))
+			pasted = min(pasted, len(in))
+			in = append(in[:pasted:pasted], translateKillKeys(in[pasted:])...)
+			if kf.t.editMode == ViMode {
+				in = translateEsc(in)
+			}
+		}
+		kf.pending = in
+	}
+	n := copy(buf, kf.pending)
+	kf.pending = kf.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,131 @@
+package terminal
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// Number of killed texts kept for Control-Y/Alt-Y.
+const killRingSize = 10
+
+// Private use runes replacing the kill and yank keys in the input: the term editor would otherwise
+// handle Control-W, Control-K and Control-U itself (without keeping the killed text) and swallow Alt-Y.
+const (
+	killWordKey    = '\uf8fb' // Control-W, kill the previous word.
+	killToEndKey   = '\uf8fa' // Control-K, kill to the end of the line.
+	killToStartKey = '\uf8f9' // Control-U, kill to the start of the line.
+	yankKey        = '\uf8f8' // Control-Y, insert the last killed text.
+	yankPopKey     = '\uf8f7' // Alt-Y, replace the text just yanked by the previous killed one.
+)
+
+var killKeys = map[byte]rune{
+	'W' - '@': killWordKey,
+	'K' - '@': killToEndKey,
+	'U' - '@': killToStartKey,
+	'Y' - '@': yankKey,
+}
+
+// killRing holds the recently killed texts (most recent last) and what's needed to extend the
+// last kill or rotate the last yank.
+type killRing struct {
+	entries  []string
+	lastLine string // line and position after the last kill or yank, to detect consecutive ones.
+	lastPos  int
+	lastKill bool // last action was a kill (vs a yank).
+	yankIdx  int  // index in entries of the last yanked text.
+	yankLen  int  // length in bytes of the last yanked text.
+}
+
+// Returns whether line and pos are still what the last kill or yank left.
+func (kr *killRing) consecutive(line string, pos int) bool {
+	return len(kr.entries) > 0 && line == kr.lastLine && pos == kr.lastPos
+}
+
+// Adds (or, for consecutive kills, appends or prepends to the last entry) killed text.
+func (kr *killRing) kill(killed string, before bool, line string, pos int) {
+	switch {
+	case kr.consecutive(line, pos) && kr.lastKill:
+		last := &kr.entries[len(kr.entries)-1]
+		if before {
+			*last = killed + *last
+		} else {
+			*last += killed
+		}
+	default:
+		kr.entries = append(kr.entries, killed)
+		if len(kr.entries) > killRingSize {
+			kr.entries = kr.entries[1:]
+		}
+	}
+	kr.lastKill = true
+}
+
+// killKey handles the kill and yank keys. Words are sequences of non space runes (like for the
+// vi mode w and b motions), so multibyte letters are never split. Consecutive kills accumulate in
+// a single entry, Control-Y inserts the most recent entry at the cursor and Alt-Y, right after a
+// Control-Y (or Alt-Y), replaces the inserted text by the previous entry, cycling through the ring.
+func (t *Terminal) killKey(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	kr := &t.kills
+	var start, end int
+	switch key {
+	case killWordKey:
+		r := []rune(line[:pos])
+		start, end = len(string(r[:viPrevWord(r, len(r))])), pos
+	case killToEndKey:
+		start, end = pos, len(line)
+	case killToStartKey:
+		start, end = 0, pos
+	case yankKey:
+		if len(kr.entries) == 0 {
+			return line, pos, true
+		}
+		kr.yankIdx = len(kr.entries) - 1
+		return kr.yank(line[:pos], line[pos:])
+	case yankPopKey:
+		if !kr.consecutive(line, pos) || kr.lastKill {
+			return line, pos, true
+		}
+		kr.yankIdx = (kr.yankIdx + len(kr.entries) - 1) % len(kr.entries)
+		return kr.yank(line[:pos-kr.yankLen], line[pos:])
+	default:
+		return
+	}
+	newLine, newPos = line[:start]+line[end:], start
+	if start != end {
+		kr.kill(line[start:end], key != killToEndKey, line, pos)
+		kr.lastLine, kr.lastPos = newLine, newPos
+	}
+	return newLine, newPos, true
+}
+
+// Inserts the yankIdx entry between before and after.
+func (kr *killRing) yank(before, after string) (string, int, bool) {
+	s := kr.entries[kr.yankIdx]
+	kr.yankLen = len(s)
+	kr.lastKill = false
+	kr.lastLine, kr.lastPos = before+s+after, len(before)+len(s)
+	return kr.lastLine, kr.lastPos, true
+}
+
+// Replaces the kill and yank keys in the input by the corresponding private use runes (pasted
+// text is left as is; for pastes spanning several reads, the caller skips the other chunks).
+func translateKillKeys(in []byte) []byte {
+	if bytes.Contains(in, pasteStart) {
+		return in
+	}
+	out := make([]byte, 0, len(in))
+	for i := 0; i < len(in); i++ {
+		b := in[i]
+		if k, found := killKeys[b]; found {
+			out = utf8.AppendRune(out, k)
+			continue
+		}
+		if b == KeyEscape && i+1 < len(in) && in[i+1] == 'y' {
+			out = utf8.AppendRune(out, yankPopKey)
+			i++
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
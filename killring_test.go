@@ -0,0 +1,81 @@
+package terminal
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"fortio.org/term"
+)
+
+// Returns a Terminal reading what's written to the returned pipe and writing to out.
+func newPipeTerminal(t *testing.T, out io.Writer) (*Terminal, *os.File) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected pipe error: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	tt := &Terminal{intrReader: NewInterruptReader(r, 256), statusW: &statusWriter{out: out}}
+	tt.keys = &keyFilter{t: tt}
+	tt.term = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{tt.keys, tt.statusW}, "")
+	tt.term.AutoCompleteCallback = tt.autoComplete
+	tt.term.AutoHistory(false) // like Open.
+	tt.Out = tt.term
+	_, cancel := tt.intrReader.Start(context.Background())
+	t.Cleanup(cancel)
+	return tt, w
+}
+
+func TestKillRing(t *testing.T) {
+	tt, w := newPipeTerminal(t, io.Discard)
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"héllo wörld\x17\x17\x19", "héllo wörld"}, // consecutive kills are one entry.
+		{"abc  déf\x01\x0bx\x19", "xabc  déf"},     // Control-A then kill to the end.
+		{"1 \x19\033y", "1 héllo wörld"},           // yank then replaced by the previous kill.
+		{"1 \x19\033y\033y", "1 abc  déf"},         // cycling back.
+		{"foo bar\x15\x19\x19", "foo barfoo bar"},
+		{"a b\x17\x17\x17c", "c"},       // killing nothing.
+		{"x\033y", "x"},                 // Alt-Y not after a yank does nothing.
+		{"ab\x02\x02\x17\x19", "a bab"}, // Control-B twice, nothing to kill before the cursor.
+	}
+	for _, tc := range tests {
+		if _, err := w.WriteString(tc.input + "\r"); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+		line, err := tt.ReadLine()
+		if err != nil || line != tc.expected {
+			t.Errorf("for %q got %q (%v), expected %q", tc.input, line, err, tc.expected)
+		}
+	}
+}
+
+func TestKillKeysInSplitPaste(t *testing.T) {
+	tt, w := newPipeTerminal(t, io.Discard)
+	kf := &keyFilter{t: tt}
+	buf := make([]byte, 256)
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"\033[200~ab\x17", "\033[200~ab\x17"},                               // paste start.
+		{"c\x0b\x15d\033y", "c\x0b\x15d\033y"},                               // paste continued, in a later read.
+		{"e\x19\033[201~\x17", "e\x19\033[201~" + string(rune(killWordKey))}, // paste end then a typed key.
+		{"f\x17", "f" + string(rune(killWordKey))},                           // typed again.
+	} {
+		if _, err := w.WriteString(tc.input); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+		n, err := kf.Read(buf)
+		if err != nil || string(buf[:n]) != tc.expected {
+			t.Errorf("for %q got %q (%v), expected %q", tc.input, buf[:n], err, tc.expected)
+		}
+	}
+}
@@ -146,6 +146,7 @@ type Game struct {
 	state                  GameState
 	showInfo               bool
 	showHelp               bool
+	showStats              bool
 	generation             uint64
 	lastClickX, lastClickY int
 	delta                  int // which 1/2 pixel we're targeting with the mouse.
@@ -159,6 +160,8 @@ func Main() int {
 	flagRandomFill := flag.Float64("fill", 0.1, "Random fill factor (0 to 1)")
 	flagGlider := flag.Bool("glider", false, "Start with a glider (default is random)")
 	noMouseFlag := flag.Bool("nomouse", false, "Disable mouse tracking")
+	maxBpsFlag := flag.Int("max-bps", 0, "Limit output to this many bytes per second, dropping frames (e.g. for slow ssh links)")
+	idleFPSFlag := flag.Float64("idle-fps", 2, "Frames per second when paused and idle (0 to disable)")
 	cli.Main()
 	game := &Game{hasMouse: !*noMouseFlag}
 	ap := ansipixels.NewAnsiPixels(*fpsFlag)
@@ -167,6 +170,8 @@ func Main() int {
 		return log.FErrf("Error opening AnsiPixels: %v", err)
 	}
 	game.ap = ap
+	ap.SetMaxBytesPerSecond(*maxBpsFlag)
+	ap.IdleFPS = *idleFPSFlag
 	defer game.End()
 	ap.HideCursor()
 	if game.hasMouse {
@@ -213,10 +218,16 @@ func Main() int {
 			continue
 		}
 		switch ap.Data[0] {
-		case 'q', 'Q', 3:
+		case 3:
 			return 0
+		case 'q', 'Q':
+			if ap.Confirm("Quit?") {
+				return 0
+			}
 		case 'i', 'I':
 			game.showInfo = !game.showInfo
+		case 'p', 'P':
+			game.showStats = !game.showStats
 		case '?', 'h', 'H':
 			game.showHelp = true
 			game.state = Paused
@@ -236,8 +247,11 @@ func (g *Game) DrawOne() {
 		g.ap.WriteRight(g.ap.H-1, "%s FPS %.0f Generation: %d ", g.state, g.ap.FPS, g.generation)
 	}
 	Draw(g.ap, g.c)
+	if g.showStats {
+		g.ap.DrawStatsOverlay()
+	}
 	if g.showHelp {
-		helpText := "Space to pause, q to quit, i for info, other key to run\n"
+		helpText := "Space to pause, q to quit, i for info, p for perf stats, other key to run\n"
 		if g.hasMouse {
 			helpText += "Left click or hold to set, right click to clear\nHold a modifier or click in same spot for other half pixel"
 		} else {
@@ -250,6 +264,10 @@ func (g *Game) DrawOne() {
 }
 
 func (g *Game) Next() {
+	if g.state == Running {
+		g.ap.RecordFrame()
+		g.ap.Active()
+	}
 	g.c.Next()
 	g.generation++
 	g.DrawOne()
@@ -261,6 +279,10 @@ func (g *Game) End() {
 	g.ap.ShowCursor()
 	g.ap.MoveCursor(0, g.ap.H-2)
 	g.ap.Restore()
+	if h := g.ap.FrameHistogram(); h != nil && h.Count > 0 {
+		p := h.Export().CalcPercentiles([]float64{50, 99})
+		log.Infof("Frame time p50 %.2f ms, p99 %.2f ms (%d frames)", p.Percentiles[0].Value, p.Percentiles[1].Value, h.Count)
+	}
 }
 
 func (g *Game) HandleMouse() {
@@ -292,7 +314,7 @@ func (g *Game) HandleMouse() {
 		log.LogVf("Mouse left (%06b) alt %t click (drag %t) at %d, %d", g.ap.Mbuttons, modifier, ld, g.ap.Mx, g.ap.My)
 		g.c.SetCurrent(g.ap.Mx-1, (g.ap.My-1)*2+delta)
 		g.lastWasClick = true
-		g.ap.MouseTrackingOn() // needed for drag, other ap.MouseClickOn() is enough.
+		g.ap.BeginDrag() // enables tracking, needed for drag, otherwise clicks only are enough.
 		if ld {
 			g.DrawOne()
 			return
@@ -306,7 +328,7 @@ func (g *Game) HandleMouse() {
 			g.ap.Mbuttons, g.ap.Mx, g.ap.My,
 			prevWasClick, sameSpot, leftDrag)
 		if prevWasClick {
-			g.ap.MouseClickOn() // turns off drag and back to just clicks.
+			g.ap.EndDrag() // turns off drag and back to just clicks.
 		}
 		return
 	}
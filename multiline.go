@@ -0,0 +1,129 @@
+package terminal
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultContinuationPrompt is the prompt of the continuation lines in multiline mode.
+const DefaultContinuationPrompt = "... "
+
+// Private use runes replacing the up and down arrows while editing continuation lines: the term
+// editor would otherwise use them for the history.
+const (
+	multilineUpKey   = '\uf8f6'
+	multilineDownKey = '\uf8f5'
+)
+
+// multiline is the state of the multiline mode, see [SetMultiline].
+type multiline struct {
+	isComplete func(text string) bool
+	prompt     string   // continuation prompt.
+	block      []string // logical lines of the block being read.
+	idx        int      // index in block of the line being edited.
+}
+
+// SetMultiline turns on (or off) the multiline mode: when the text entered so far isn't complete
+// according to isComplete (e.g. because of unbalanced braces for a REPL), ReadLine keeps reading
+// lines, with the continuation prompt (see [SetContinuationPrompt]), and then returns them joined
+// with \n. A nil isComplete treats lines ending with \ as incomplete. While editing a continuation
+// line, the up and down arrows move to the previous and next lines of the block; Enter on a
+// previous line keeps the edit and goes back to the last line. The whole block becomes a single
+// history entry (when [AutoHistory] is on).
+func (t *Terminal) SetMultiline(enabled bool, isComplete func(text string) bool) {
+	if !enabled {
+		t.multiline = nil
+		return
+	}
+	if isComplete == nil {
+		isComplete = func(text string) bool { return !strings.HasSuffix(text, "\\") }
+	}
+	prompt := DefaultContinuationPrompt
+	if t.multiline != nil {
+		prompt = t.multiline.prompt
+	}
+	t.multiline = &multiline{isComplete: isComplete, prompt: prompt}
+}
+
+// SetContinuationPrompt sets the prompt of the multiline mode continuation lines (instead of
+// [DefaultContinuationPrompt]). Must be called after SetMultiline.
+func (t *Terminal) SetContinuationPrompt(prompt string) {
+	if t.multiline != nil {
+		t.multiline.prompt = prompt
+	}
+}
+
+// Reads lines until the block is complete, see SetMultiline.
+func (t *Terminal) readMultiline() (string, error) {
+	ml := t.multiline
+	ml.block, ml.idx = []string{""}, 0
+	defer func() {
+		ml.block = nil
+		t.setContinuation("")
+	}()
+	for {
+		c, err := t.readLine()
+		if err != nil {
+			return c, err
+		}
+		ml.block[ml.idx] = c
+		if last := len(ml.block) - 1; ml.idx < last {
+			ml.idx = last // edited a previous line, back to editing the last one.
+			t.SetLine(ml.block[last], len(ml.block[last]))
+			continue
+		}
+		text := strings.Join(ml.block, "\n")
+		if ml.isComplete(text) {
+			if t.autoHistory && strings.TrimSpace(text) != "" {
+				t.addHistory(text, time.Now())
+			}
+			return text, nil
+		}
+		ml.block = append(ml.block, "")
+		ml.idx++
+		t.setContinuation(ml.prompt)
+	}
+}
+
+// Sets the continuation prompt, or restores the normal one with "".
+func (t *Terminal) setContinuation(prompt string) {
+	t.promptMu.Lock()
+	defer t.promptMu.Unlock()
+	t.continuing = prompt != ""
+	if !t.continuing {
+		prompt = t.prompt
+	}
+	t.term.SetPrompt(prompt)
+}
+
+// Handles the up and down arrows while editing a continuation line.
+func (t *Terminal) multilineKey(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	ml := t.multiline
+	if ml == nil || len(ml.block) < 2 || (key != multilineUpKey && key != multilineDownKey) {
+		return
+	}
+	next := ml.idx - 1
+	if key == multilineDownKey {
+		next = ml.idx + 1
+	}
+	if next < 0 || next >= len(ml.block) {
+		return line, pos, true
+	}
+	ml.block[ml.idx] = line
+	ml.idx = next
+	return ml.block[next], len(ml.block[next]), true
+}
+
+// Replaces the up and down arrows by the multiline keys when editing a continuation line.
+func (t *Terminal) multilineInput(in []byte) []byte {
+	if t.multiline == nil || len(t.multiline.block) < 2 {
+		return in
+	}
+	switch string(in) {
+	case "\033[A", "\033OA":
+		return []byte(string(multilineUpKey))
+	case "\033[B", "\033OB":
+		return []byte(string(multilineDownKey))
+	}
+	return in
+}
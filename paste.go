@@ -0,0 +1,71 @@
+package terminal
+
+import "bytes"
+
+// pasteState tracks the bracketed paste content, see LastPaste.
+type pasteState struct {
+	pasting      bool
+	buf          []byte
+	partial      []byte // possible start of a marker at the end of the last input chunk.
+	lastPaste    string
+	lastWasPaste bool
+}
+
+// LastPaste returns the content of the last bracketed paste (without the markers, complete even
+// if it spanned several lines or reads) and whether the line returned by the last ReadLine came
+// from a paste, e.g. to handle a pasted multiline blob as a single unit.
+func (t *Terminal) LastPaste() (text string, wasPaste bool) {
+	return t.paste.lastPaste, t.paste.lastWasPaste
+}
+
+// Called with each input chunk to capture the bracketed paste content (between the pasteStart
+// and pasteEnd markers, which can be split across chunks).
+func (t *Terminal) trackPaste(in []byte) {
+	ps := &t.paste
+	data := append(ps.partial, in...)
+	ps.partial = nil
+	for len(data) > 0 {
+		marker := pasteStart
+		if ps.pasting {
+			marker = pasteEnd
+		}
+		i := bytes.Index(data, marker)
+		if i == -1 {
+			keep := partialSuffix(data, marker)
+			if ps.pasting {
+				ps.buf = append(ps.buf, data[:len(data)-keep]...)
+			}
+			ps.partial = bytes.Clone(data[len(data)-keep:])
+			return
+		}
+		if ps.pasting {
+			ps.buf = append(ps.buf, data[:i]...)
+			ps.lastPaste = string(ps.buf)
+			ps.buf = nil
+		}
+		ps.pasting = !ps.pasting
+		data = data[i+len(marker):]
+	}
+}
+
+// Returns the length of the start of the input chunk that continues a paste begun in a previous
+// chunk (up to and including the end marker), 0 if not pasting.
+func (t *Terminal) pastedPrefix(in []byte) int {
+	if !t.paste.pasting {
+		return 0
+	}
+	if i := bytes.Index(in, pasteEnd); i >= 0 {
+		return i + len(pasteEnd)
+	}
+	return len(in)
+}
+
+// Returns the length of the longest suffix of data that is a (strict) prefix of marker.
+func partialSuffix(data, marker []byte) int {
+	for n := min(len(data), len(marker)-1); n > 0; n-- {
+		if bytes.HasSuffix(data, marker[:n]) {
+			return n
+		}
+	}
+	return 0
+}
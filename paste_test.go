@@ -0,0 +1,17 @@
+package terminal
+
+import "testing"
+
+func TestTrackPaste(t *testing.T) {
+	tt := &Terminal{}
+	for _, chunk := range []string{"ab\033[20", "0~line 1\r\nli", "ne 2\033", "[201~cd"} {
+		tt.trackPaste([]byte(chunk))
+	}
+	if text, _ := tt.LastPaste(); text != "line 1\r\nline 2" {
+		t.Errorf("unexpected paste %q", text)
+	}
+	tt.trackPaste([]byte("\033[200~x\033[201~\033[200~y"))
+	if text, _ := tt.LastPaste(); text != "x" || !tt.paste.pasting {
+		t.Errorf("unexpected paste %q (pasting %t)", text, tt.paste.pasting)
+	}
+}
@@ -0,0 +1,76 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+)
+
+// Private use rune injected in the input when the ReadLineContext context is done, to clear the
+// line being edited before the read is ended.
+const abortLineKey = '\uf8f4'
+
+var abortLineInput = []byte(string(abortLineKey))
+
+// ReadLineContext is like ReadLine but also returns, with ctx.Err() (e.g.
+// context.DeadlineExceeded), when ctx is done before a line is entered. For instance for a
+// "continue? [y/N]" prompt answering itself after a while:
+//
+//	ctx, cancel := context.WithTimeout(t.Context, 10*time.Second)
+//	defer cancel()
+//	answer, err := t.ReadLineContext(ctx)
+//
+// When that happens, the partially typed line, if any, is discarded (it isn't returned nor added
+// to the history) and the prompt and line are erased from the screen, so the next ReadLine starts
+// afresh on the same screen line. Keys typed after the expiration are kept for the next ReadLine.
+func (t *Terminal) ReadLineContext(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	t.readCtx = ctx
+	stop := context.AfterFunc(ctx, func() { t.intrReader.unread(abortLineInput) })
+	defer func() {
+		stop()
+		t.readCtx = nil
+	}()
+	c, err := t.ReadLine()
+	if err != nil && err == ctx.Err() { //nolint:errorlint // we want the context error itself.
+		// The line is now empty, erase the prompt too (restored by the next ReadLine).
+		t.term.SetPrompt("")
+		_, _ = t.term.Write(nil)
+		t.term.SetPrompt(t.currentPrompt())
+	}
+	return c, err
+}
+
+// Handles the key injected when the ReadLineContext context is done: clears the line and makes
+// the next read return the context error. Left over keys from a previous ReadLineContext are
+// ignored.
+func (t *Terminal) abortKey(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	if key != abortLineKey {
+		return
+	}
+	if t.readCtx == nil || t.readCtx.Err() == nil {
+		return line, pos, true
+	}
+	t.aborted = true
+	return "", 0, true
+}
+
+// Returns the error ending the ReadLineContext once the context is done and the line cleared.
+func (t *Terminal) abortError() error {
+	if !t.aborted {
+		return nil
+	}
+	t.aborted = false
+	return t.readCtx.Err()
+}
+
+// Separates the injected abort key from the input following it (left for the next reads), so
+// that input isn't added to the line about to be discarded.
+func (t *Terminal) abortInput(in []byte) []byte {
+	if len(in) > len(abortLineInput) && bytes.HasPrefix(in, abortLineInput) {
+		t.intrReader.unread(in[len(abortLineInput):])
+		return in[:len(abortLineInput)]
+	}
+	return in
+}
@@ -0,0 +1,142 @@
+package terminal
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// Special keys returned by [Terminal.ReadKey]. Like in term, they use the UTF-16 surrogate
+// range so they can't collide with actual (valid) runes.
+const (
+	KeyUnknown rune = 0xd800 + iota
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyInsert
+	KeyDelete
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+const (
+	KeyEscape = 27
+	CtrlD     = 4 // Control-D, EOF.
+)
+
+// ReadKey reads and returns the next single keypress, without echo nor history, for instance for
+// y/n confirmations. Special keys like arrows are decoded into the Key* constants, Enter is '\r'.
+// Like [ReadLine], io.EOF is returned for Control-D and ErrInterrupted when the user presses
+// Control-C or a signal is received. Extra input read along with the key is kept for the next
+// ReadKey or ReadLine, as are the keys typed ahead during the previous ReadLine.
+func (t *Terminal) ReadKey() (rune, error) {
+	t.unreadPending()
+	buf := make([]byte, 64)
+	n, err := t.intrReader.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	buf = buf[:n]
+	key, size := decodeKey(buf)
+	t.intrReader.unread(buf[size:])
+	if key == CtrlD {
+		return 0, io.EOF
+	}
+	return key, nil
+}
+
+// Puts back the input read by the editor's keyFilter but not yet given to the editor, so it's
+// read (in order) by ReadKey and ReadEvent. The editor itself never reads past the end of the
+// line (see lineEndInput).
+func (t *Terminal) unreadPending() {
+	if t.keys == nil || len(t.keys.pending) == 0 {
+		return
+	}
+	t.intrReader.unread(t.keys.pending)
+	t.keys.pending = nil
+}
+
+// Called with each input chunk given to the editor: the keys after the first one ending the line
+// (Enter, or Control-C and Control-D which may end it too), unless pasted, are unread so they
+// don't stay buffered in the editor, out of reach of ReadKey and ReadEvent, once ReadLine returns.
+func (t *Terminal) lineEndInput(in []byte) []byte {
+	i := bytes.IndexAny(in, "\r\x03\x04")
+	if i < 0 || t.paste.pasting || bytes.Contains(in[:i], pasteStart) {
+		return in
+	}
+	t.intrReader.unread(in[i+1:])
+	return in[:i+1]
+}
+
+// Returns the first key in buf and how many bytes it uses.
+func decodeKey(buf []byte) (rune, int) {
+	if buf[0] != KeyEscape || len(buf) < 3 || (buf[1] != '[' && buf[1] != 'O') {
+		r, size := utf8.DecodeRune(buf)
+		return r, size
+	}
+	switch buf[2] {
+	case 'A':
+		return KeyUp, 3
+	case 'B':
+		return KeyDown, 3
+	case 'C':
+		return KeyRight, 3
+	case 'D':
+		return KeyLeft, 3
+	case 'H':
+		return KeyHome, 3
+	case 'F':
+		return KeyEnd, 3
+	}
+	if buf[1] == 'O' && buf[2] >= 'P' && buf[2] <= 'S' {
+		return KeyF1 + rune(buf[2]-'P'), 3
+	}
+	// CSI sequences: parameters until the final byte (0x40 to 0x7e).
+	end := bytes.IndexFunc(buf[2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+	if end == -1 {
+		return KeyUnknown, len(buf)
+	}
+	size := end + 3
+	if buf[size-1] != '~' {
+		return KeyUnknown, size
+	}
+	switch string(buf[2 : size-1]) {
+	case "1", "7":
+		return KeyHome, size
+	case "2":
+		return KeyInsert, size
+	case "3":
+		return KeyDelete, size
+	case "4", "8":
+		return KeyEnd, size
+	case "5":
+		return KeyPageUp, size
+	case "6":
+		return KeyPageDown, size
+	case "11", "12", "13", "14", "15":
+		return KeyF1 + rune(buf[3]-'1'), size
+	case "17", "18", "19", "20", "21":
+		return KeyF6 + rune((buf[2]-'0')*10+buf[3]-'0'-17), size
+	case "23", "24":
+		return KeyF11 + rune(buf[3]-'3'), size
+	}
+	return KeyUnknown, size
+}
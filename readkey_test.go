@@ -0,0 +1,37 @@
+package terminal
+
+import (
+	"io"
+	"testing"
+)
+
+func TestReadKeyAfterReadLine(t *testing.T) {
+	tt, w := newPipeTerminal(t, io.Discard)
+	// Keys typed ahead, in the same read as the line.
+	if _, err := w.WriteString("ab\rx\033[Ay"); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	line, err := tt.ReadLine()
+	if err != nil || line != "ab" {
+		t.Fatalf("unexpected line %q (%v)", line, err)
+	}
+	if _, err = w.WriteString("z"); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	for _, expected := range []rune{'x', KeyUp, 'y', 'z'} {
+		key, err := tt.ReadKey()
+		if err != nil || key != expected {
+			t.Errorf("got key %q (%v), expected %q", key, err, expected)
+		}
+	}
+	// And the other way around.
+	if _, err = w.WriteString("kcd\r"); err != nil {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	if key, err := tt.ReadKey(); err != nil || key != 'k' {
+		t.Errorf("got key %q (%v), expected 'k'", key, err)
+	}
+	if line, err = tt.ReadLine(); err != nil || line != "cd" {
+		t.Errorf("unexpected line %q (%v)", line, err)
+	}
+}
@@ -0,0 +1,47 @@
+package terminal
+
+import (
+	"fortio.org/log"
+	"fortio.org/term"
+)
+
+// UpdateSize reads the terminal size and gives it to the line editor, which redraws the line being
+// edited (wrapping it for the new width) when the width changed, and then calls OnResize, if set.
+// It is called automatically when the terminal is resized, unless turned off with
+// [SetAutoResize].
+func (t *Terminal) UpdateSize() error {
+	w, h, err := term.GetSize(t.fdOut)
+	if err != nil {
+		return err
+	}
+	if w == t.width && h == t.height {
+		return nil
+	}
+	t.width, t.height = w, h
+	t.statusW.setWidth(w) // before SetSize, so its redraw puts the right prompt at the new edge.
+	if err = t.term.SetSize(w, h); err != nil {
+		return err
+	}
+	if t.OnResize != nil {
+		t.OnResize(w, h)
+	}
+	return nil
+}
+
+// SetAutoResize turns on (the default after Open, for terminals) or off the automatic
+// [UpdateSize] calls when the terminal is resized (on SIGWINCH or, where there is no such signal,
+// by polling the size a few times per second).
+func (t *Terminal) SetAutoResize(enabled bool) {
+	if t.stopResize != nil {
+		close(t.stopResize)
+		t.stopResize = nil
+	}
+	if !enabled || !t.IsTerminal() {
+		return
+	}
+	if err := t.UpdateSize(); err != nil {
+		log.Errf("Error getting terminal size: %v", err)
+	}
+	t.stopResize = make(chan struct{})
+	go t.watchResize(t.stopResize)
+}
@@ -0,0 +1,29 @@
+//go:build !unix
+// +build !unix
+
+package terminal
+
+import (
+	"time"
+
+	"fortio.org/log"
+)
+
+// How often the size is checked, in the absence of SIGWINCH.
+const resizePollInterval = 250 * time.Millisecond
+
+// Calls UpdateSize periodically (it does nothing when the size didn't change) until stop is closed.
+func (t *Terminal) watchResize(stop chan struct{}) {
+	ticker := time.NewTicker(resizePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := t.UpdateSize(); err != nil {
+				log.Errf("Error getting terminal size: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+//go:build unix
+// +build unix
+
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"fortio.org/log"
+)
+
+// Calls UpdateSize on each SIGWINCH until stop is closed.
+func (t *Terminal) watchResize(stop chan struct{}) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGWINCH)
+	defer signal.Stop(sigc)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-sigc:
+			if err := t.UpdateSize(); err != nil {
+				log.Errf("Error getting terminal size: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+
+	"github.com/rivo/uniseg"
+)
+
+var ansiRE = regexp.MustCompile("\x1b\\[[0-9;?]*[@-~]")
+
+// Returns the width of s on screen, ignoring the ANSI codes and counting wide runes as 2.
+func screenWidth(s string) int {
+	return uniseg.StringWidth(ansiRE.ReplaceAllString(s, ""))
+}
+
+// SetRightPrompt sets (or clears, with "") a prompt shown flush right on the prompt line (like
+// zsh's RPROMPT), e.g. for the current git branch or time. It can include colors. It is hidden
+// while the line being edited would get too close to it and moved to the new right edge when the
+// terminal is resized. It uses the width last read by [UpdateSize] (called by Open unless the
+// automatic resize is turned off).
+func (t *Terminal) SetRightPrompt(s string) {
+	if t.statusW == nil || !t.IsTerminal() {
+		return
+	}
+	t.rprompt = s
+	t.updateRightPrompt()
+}
+
+// RightPrompt returns the current right prompt.
+func (t *Terminal) RightPrompt() string {
+	return t.rprompt
+}
+
+// Shows or hides the right prompt according to the last known width of the edited line.
+func (t *Terminal) updateRightPrompt() {
+	used := -1
+	if t.rpActive {
+		used = screenWidth(t.currentPrompt()) + t.rpLineW
+	}
+	t.statusW.setRightPrompt(t.rprompt, used)
+}
+
+// Called by autoComplete with the line before and after key to update the edited line width.
+func (t *Terminal) rightPromptKey(line string, key rune, newLine string, ok bool) {
+	switch {
+	case ok:
+		t.rpLineW = screenWidth(newLine)
+	case key >= ' ' && (key < 0xd800 || key > 0xdbff): // printable, see term.isPrintable.
+		t.rpLineW = screenWidth(line + string(key))
+	default:
+		return
+	}
+	t.updateRightPrompt()
+}
+
+// Called with each input chunk before the editor sees it: hides the right prompt before Enter
+// (the output would then continue on the next line) and for the keys changing the line without
+// calling autoComplete (history recall), until the new line width is known.
+func (t *Terminal) rightPromptInput(in []byte) {
+	if t.rprompt == "" || len(in) == 0 {
+		return
+	}
+	switch {
+	case bytes.ContainsAny(in, "\r\n"):
+		t.rpActive = false
+	case bytes.HasPrefix(in, []byte("\033[A")), bytes.HasPrefix(in, []byte("\033[B")),
+		bytes.HasPrefix(in, []byte("\033OA")), bytes.HasPrefix(in, []byte("\033OB")),
+		in[0] == 'P'-'@', in[0] == 'N'-'@':
+		t.rpLineW = math.MaxInt32 // unknown, until the next key.
+	default:
+		return
+	}
+	t.updateRightPrompt()
+}
+
+// setRightPrompt sets the right prompt and the width used on its line by the prompt and edited
+// line, -1 to hide it.
+func (sw *statusWriter) setRightPrompt(s string, used int) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	sw.rprompt, sw.rpUsed = s, used
+	sw.placeRightPrompt()
+}
+
+// setWidth sets the terminal width, which the right prompt is flush against.
+func (sw *statusWriter) setWidth(w int) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.rpShownAt != 0 { // the old position may have been wrapped or cut by the resize.
+		_, _ = io.WriteString(sw.out, sw.rightPromptErase())
+		sw.rpShownAt = 0
+	}
+	sw.width = w
+	sw.placeRightPrompt()
+}
+
+// Sets the column to draw the right prompt at (drawn with the next editor output), 0 to hide it
+// (erased right away).
+func (sw *statusWriter) placeRightPrompt() {
+	rpW := screenWidth(sw.rprompt)
+	sw.rpCol = 0
+	// At least a space between the edited line (and the cursor after it) and the right prompt.
+	if rpW > 0 && sw.rpUsed >= 0 && sw.width > 0 && sw.rpUsed+2 < sw.width-rpW+1 {
+		sw.rpCol = sw.width - rpW + 1
+	}
+	if sw.rpCol == 0 && sw.rpShownAt != 0 {
+		_, _ = io.WriteString(sw.out, sw.rightPromptErase())
+		sw.rpShownAt = 0
+	}
+}
+
+// Returns the sequence erasing the right prompt from the column it was drawn at (the cursor
+// must be on the prompt line).
+func (sw *statusWriter) rightPromptErase() string {
+	return fmt.Sprintf("%s\033[%dG\033[K%s", saveCursor, sw.rpShownAt, restoreCursor)
+}
+
+// Returns the sequence drawing the right prompt, erasing it first if it moved (resize).
+func (sw *statusWriter) rightPromptDraw() string {
+	erase := ""
+	if sw.rpShownAt != 0 && sw.rpShownAt != sw.rpCol {
+		erase = fmt.Sprintf("\033[%dG\033[K", min(sw.rpShownAt, sw.rpCol))
+	}
+	sw.rpShownAt = sw.rpCol
+	return fmt.Sprintf("%s%s\033[%dG%s\033[0m%s", saveCursor, erase, sw.rpCol, sw.rprompt, restoreCursor)
+}
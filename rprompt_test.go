@@ -0,0 +1,30 @@
+package terminal
+
+import (
+	"io"
+	"testing"
+)
+
+func TestRightPromptColumn(t *testing.T) {
+	sw := &statusWriter{out: io.Discard}
+	sw.setRightPrompt("main", 10) // width not known yet.
+	if sw.rpCol != 0 {
+		t.Errorf("rpCol = %d before the width is known, want 0", sw.rpCol)
+	}
+	sw.setWidth(40)
+	if sw.rpCol != 37 {
+		t.Errorf("rpCol = %d, want 37", sw.rpCol)
+	}
+	sw.setRightPrompt("main", 35) // a space is kept after the cursor.
+	if sw.rpCol != 0 {
+		t.Errorf("rpCol = %d with the line too long, want 0", sw.rpCol)
+	}
+	sw.setWidth(80) // resizing puts it back, at the new right edge, without any key.
+	if sw.rpCol != 77 {
+		t.Errorf("rpCol = %d after resize, want 77", sw.rpCol)
+	}
+	sw.setRightPrompt("main", -1) // not reading a line.
+	if sw.rpCol != 0 {
+		t.Errorf("rpCol = %d when not reading, want 0", sw.rpCol)
+	}
+}
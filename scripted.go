@@ -0,0 +1,134 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fortio.org/log"
+)
+
+// ScriptedReader is an io.Reader returning a scripted sequence of input chunks (keys, mouse
+// events, captured byte streams...) at a controlled rate, for instance to benchmark or test
+// input handling deterministically (see AnsiPixels.SetInput). Each Read returns at most one
+// chunk, after waiting Interval (or the chunk's Delays entry). io.EOF is returned once all
+// the chunks have been read.
+type ScriptedReader struct {
+	Chunks   [][]byte
+	Interval time.Duration   // Delay before returning each chunk, 0 for as fast as possible.
+	Delays   []time.Duration // Optional per chunk delays (e.g. from a capture), overrides Interval.
+	next     int
+	partial  []byte // rest of the current chunk if it didn't fit in the Read buffer.
+}
+
+// NewScriptedReader returns a ScriptedReader for the given chunks.
+func NewScriptedReader(interval time.Duration, chunks ...[]byte) *ScriptedReader {
+	return &ScriptedReader{Chunks: chunks, Interval: interval}
+}
+
+func (sr *ScriptedReader) Read(p []byte) (int, error) {
+	if len(sr.partial) == 0 {
+		if sr.next >= len(sr.Chunks) {
+			return 0, io.EOF
+		}
+		delay := sr.Interval
+		if sr.next < len(sr.Delays) {
+			delay = sr.Delays[sr.next]
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		sr.partial = sr.Chunks[sr.next]
+		sr.next++
+	}
+	n := copy(p, sr.partial)
+	sr.partial = sr.partial[n:]
+	return n, nil
+}
+
+// Rewind restarts the script from the first chunk (e.g. for each benchmark iteration).
+func (sr *ScriptedReader) Rewind() {
+	sr.next = 0
+	sr.partial = nil
+}
+
+// Captured input format: one line per chunk read, with the time elapsed since the previous
+// chunk (time.Duration format) and the Go quoted bytes, e.g.
+//
+//	120ms "\x1b[M *%"
+//	1.5s "q"
+
+// InputRecorder is an io.Reader wrapping another one and writing every chunk read to W
+// in the captured input format, to be replayed later with [ReplayInput] (e.g. for reproducing
+// input decoding issues of a particular terminal).
+type InputRecorder struct {
+	R    io.Reader
+	W    io.Writer
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewInputRecorder returns an InputRecorder reading from r and capturing to w.
+func NewInputRecorder(r io.Reader, w io.Writer) *InputRecorder {
+	return &InputRecorder{R: r, W: w, last: time.Now()}
+}
+
+func (ir *InputRecorder) Read(p []byte) (int, error) {
+	n, err := ir.R.Read(p)
+	if n > 0 {
+		ir.mu.Lock()
+		now := time.Now()
+		_, werr := fmt.Fprintf(ir.W, "%v %s\n", now.Sub(ir.last), strconv.Quote(string(p[:n])))
+		ir.last = now
+		ir.mu.Unlock()
+		if werr != nil {
+			log.Errf("Error writing input capture: %v", werr)
+		}
+	}
+	return n, err
+}
+
+// ReplayInput loads a captured input file (see [InputRecorder]) and returns a reader replaying
+// it with the original timing (set Delays to nil on the result for as fast as possible).
+func ReplayInput(path string) (*ScriptedReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseCapture(f)
+}
+
+// ParseCapture reads captured input from r, see [ReplayInput].
+func ParseCapture(r io.Reader) (*ScriptedReader, error) {
+	sr := &ScriptedReader{}
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		durStr, quoted, found := strings.Cut(line, " ")
+		if !found {
+			return nil, fmt.Errorf("line %d: missing space separator in %q", lineNum, line)
+		}
+		delay, err := time.ParseDuration(durStr)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		data, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: unquoting %q: %w", lineNum, quoted, err)
+		}
+		sr.Chunks = append(sr.Chunks, []byte(data))
+		sr.Delays = append(sr.Delays, delay)
+	}
+	return sr, scanner.Err()
+}
@@ -0,0 +1,170 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	CtrlG = 7  // Control-G, cancels the history search.
+	CtrlR = 18 // Control-R, starts (or continues to the next older match) the history search.
+)
+
+// Private use rune replacing backspace in the input during a history search: the term editor
+// would otherwise delete from the line without calling autoComplete.
+const searchBackspaceKey = '\uf8fc'
+
+// historySearch is the state of an in progress Control-R reverse incremental history search.
+type historySearch struct {
+	hist     []string // history, most recent first.
+	query    string
+	idx      int  // index in hist of the current match, -1 if none.
+	failing  bool // the last query change didn't match anything.
+	origLine string
+	origPos  int
+	status   string // status line to restore at the end of the search.
+}
+
+func (hs *historySearch) statusLine() string {
+	prefix := "reverse-i-search"
+	if hs.failing {
+		prefix = "failing " + prefix
+	}
+	return fmt.Sprintf("(%s)`%s'", prefix, hs.query)
+}
+
+// Returns the index of the first entry, starting at from, containing the query, -1 if none.
+func (hs *historySearch) find(from int) int {
+	for i := max(from, 0); i < len(hs.hist); i++ {
+		if strings.Contains(hs.hist[i], hs.query) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Returns the line (and position, on the match) to show for the current state.
+func (hs *historySearch) result() (string, int, bool) {
+	if hs.idx < 0 {
+		return hs.origLine, hs.origPos, true
+	}
+	h := hs.hist[hs.idx]
+	// When failing, the previous match is kept: on the longest start of the query it contains.
+	for q := hs.query; q != ""; q = q[:len(q)-1] {
+		if i := strings.Index(h, q); i >= 0 {
+			return h, i, true
+		}
+	}
+	return h, 0, true
+}
+
+// searchKey handles Control-R and the keys typed during the history search: printable keys
+// extend the query, backspace shortens it, Control-R goes to the next older match and
+// Control-G (or Esc) cancels back to the original line. ok is false for other keys.
+func (t *Terminal) searchKey(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	hs := t.search
+	if hs == nil {
+		if key != CtrlR {
+			return
+		}
+		hs = &historySearch{hist: t.History(), idx: -1, origLine: line, origPos: pos, status: t.StatusLine()}
+		t.search = hs
+		t.SetStatusLine(hs.statusLine())
+		return line, pos, true
+	}
+	switch key {
+	case CtrlG:
+		t.endSearch()
+		return hs.origLine, hs.origPos, true
+	case CtrlR:
+		if hs.query == "" {
+			return hs.result()
+		}
+		if i := hs.find(hs.idx + 1); i >= 0 {
+			hs.idx = i
+		}
+	case searchBackspaceKey:
+		if hs.query == "" {
+			return hs.result()
+		}
+		_, size := utf8.DecodeLastRuneInString(hs.query)
+		hs.query = hs.query[:len(hs.query)-size]
+		hs.idx, hs.failing = -1, false
+		if hs.query != "" {
+			hs.idx = hs.find(0)
+			hs.failing = hs.idx < 0
+		}
+	default:
+		if key < ' ' || key >= 0xd800 && key <= 0xdbff { // not printable, see term.isPrintable.
+			return
+		}
+		hs.query += string(key)
+		i := hs.find(hs.idx)
+		hs.failing = i < 0
+		if i >= 0 {
+			hs.idx = i
+		}
+	}
+	t.SetStatusLine(hs.statusLine())
+	return hs.result()
+}
+
+// Ends the history search, if any, restoring the previous status line.
+func (t *Terminal) endSearch() {
+	if t.search == nil {
+		return
+	}
+	t.SetStatusLine(t.search.status)
+	t.search = nil
+}
+
+// Called with each input chunk during a history search: keys not handled by searchKey (Enter,
+// arrows, editing keys...) end the search, keeping the current match, and are then processed
+// normally by the editor. The keys are processed one at a time (a run of printable ones being
+// a single "key"): the rest of the chunk is unread, to be processed by the next Read, as the
+// search may have ended by then.
+func (t *Terminal) searchInput(in []byte) []byte {
+	if string(in) == "\033" {
+		return []byte{CtrlG}
+	}
+	n := searchKeyLen(in)
+	if n == 0 {
+		t.endSearch()
+		return in
+	}
+	t.intrReader.unread(in[n:])
+	if in[0] == 127 || in[0] == 8 { // backspace.
+		return []byte(string(searchBackspaceKey))
+	}
+	return in[:n]
+}
+
+// Called with each input chunk when no history search is in progress: the keys after a
+// Control-R (starting a search) are unread, so they are processed by searchInput.
+func (t *Terminal) searchStartInput(in []byte) []byte {
+	i := bytes.IndexByte(in, CtrlR)
+	if i < 0 || t.paste.pasting || bytes.Contains(in, pasteStart) {
+		return in
+	}
+	t.intrReader.unread(in[i+1:])
+	return in[:i+1]
+}
+
+// Returns the length of the first key of the input chunk if it's one handled during the search,
+// 0 otherwise. A run of printable keys counts as one key.
+func searchKeyLen(in []byte) int {
+	if len(in) == 0 {
+		return 0
+	}
+	switch in[0] {
+	case 127, 8, CtrlR, CtrlG:
+		return 1
+	}
+	n := 0
+	for n < len(in) && in[n] >= ' ' && in[n] != 127 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,28 @@
+package terminal
+
+import (
+	"io"
+	"testing"
+)
+
+func TestSearchKeysInOneRead(t *testing.T) {
+	tt, w := newPipeTerminal(t, io.Discard)
+	tt.AddToHistory("make test", "ls -l", "make")
+	for _, tc := range []struct {
+		input    string
+		expected string
+	}{
+		{"\x12ls -x\x7f\x7f\r", "ls -l"}, // fast typing: several keys in a single read.
+		{"\x12tesx\x7f\x7ft\r", "make test"},
+		{"\x12zz\x7f\x7f\x7fmake\x12\r", "make test"}, // extra backspace, next match.
+		{"\x12ma\033[D\r", "make"},                    // arrow ends the search, then edits.
+	} {
+		if _, err := w.WriteString(tc.input); err != nil {
+			t.Fatalf("unexpected write error: %v", err)
+		}
+		line, err := tt.ReadLine()
+		if err != nil || line != tc.expected {
+			t.Errorf("for %q got %q (%v), expected %q", tc.input, line, err, tc.expected)
+		}
+	}
+}
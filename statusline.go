@@ -0,0 +1,126 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+// statusWriter sits between the term editor and the actual output, redrawing the
+// status line (if any) below the cursor line after each update of the prompt/edit line.
+type statusWriter struct {
+	out    io.Writer
+	mu     sync.Mutex
+	status string
+	ghost  string // auto suggestion shown (dimmed) after the cursor.
+	// Right prompt, column to draw it at (0 when hidden) and column it was last drawn at.
+	rprompt   string
+	rpCol     int
+	rpShownAt int
+	rpUsed    int // width used by the prompt and edited line, -1 when not reading a line.
+	width     int // terminal width, see UpdateSize.
+}
+
+const (
+	saveCursor    = "\0337"
+	restoreCursor = "\0338"
+	// In raw mode \n is a pure line feed (column is preserved): this makes sure there is a line
+	// below the cursor (scrolling if needed) so save/restore cursor isn't messed up by scrolling.
+	reserveLineBelow = "\n\033[A"
+	clearLineBelow   = "\n\r\033[K"
+)
+
+func (sw *statusWriter) Write(buf []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.status == "" && sw.ghost == "" && sw.rpCol == 0 {
+		return sw.out.Write(buf)
+	}
+	if sw.status != "" {
+		// Erase the status first so output going down (enter, printed text) doesn't leave remnants.
+		_, err := io.WriteString(sw.out, saveCursor+clearLineBelow+restoreCursor)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := sw.out.Write(buf)
+	if err != nil {
+		return n, err
+	}
+	if sw.ghost != "" {
+		_, err = fmt.Fprintf(sw.out, "%s%s%s\033[%dD", ghostStart, sw.ghost, ghostEnd, utf8.RuneCountInString(sw.ghost))
+		if err != nil {
+			return n, err
+		}
+	}
+	if sw.rpCol != 0 {
+		_, err = io.WriteString(sw.out, sw.rightPromptDraw())
+		if err != nil {
+			return n, err
+		}
+	}
+	if sw.status != "" {
+		_, err = io.WriteString(sw.out, sw.statusSequence())
+	}
+	return n, err
+}
+
+func (sw *statusWriter) statusSequence() string {
+	return reserveLineBelow + saveCursor + clearLineBelow + sw.status + restoreCursor
+}
+
+func (sw *statusWriter) setStatus(s string) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	prev := sw.status
+	sw.status = s
+	if s == "" {
+		if prev != "" {
+			_, _ = io.WriteString(sw.out, saveCursor+clearLineBelow+restoreCursor)
+		}
+		return
+	}
+	_, _ = io.WriteString(sw.out, sw.statusSequence())
+}
+
+// setGhost sets the ghost text drawn after the next editor output.
+func (sw *statusWriter) setGhost(s string) {
+	sw.mu.Lock()
+	sw.ghost = s
+	sw.mu.Unlock()
+}
+
+// eraseGhost clears the ghost text from the screen (the cursor must still be where it was drawn,
+// i.e. at the end of the line). Returns true if there was one.
+func (sw *statusWriter) eraseGhost() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.ghost == "" {
+		return false
+	}
+	sw.ghost = ""
+	_, _ = io.WriteString(sw.out, "\033[K")
+	return true
+}
+
+// SetStatusLine sets (or clears, with "") a persistent one line status area, shown below the
+// prompt line during ReadLine and redrawn after each keystroke and output. The status isn't part
+// of the returned line. It should fit within the terminal width (no wrapping) and for now
+// it is drawn below the cursor line, so it is best used with input that fits on one line.
+func (t *Terminal) SetStatusLine(s string) {
+	if t.statusW == nil || !t.IsTerminal() {
+		return
+	}
+	t.statusW.setStatus(s)
+}
+
+// StatusLine returns the current status line.
+func (t *Terminal) StatusLine() string {
+	if t.statusW == nil {
+		return ""
+	}
+	t.statusW.mu.Lock()
+	defer t.statusW.mu.Unlock()
+	return t.statusW.status
+}
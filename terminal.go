@@ -5,10 +5,16 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"iter"
+	"maps"
 	"os"
 	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"fortio.org/log"
 	"fortio.org/safecast"
@@ -19,19 +25,79 @@ type Terminal struct {
 	// Use this for any output to the screen/console so the required \r are added in raw mode
 	// the prompt and command edit is refresh as needed when input comes in.
 	Out io.Writer
+	// Called (from a separate goroutine) with the new width and height when the terminal is
+	// resized, see [SetAutoResize].
+	OnResize func(w, h int)
 	// Cancellable context after Open(). Use it to cancel the terminal reading or check for done.
 	Context     context.Context //nolint:containedctx // To avoid Open() returning 4 values.
 	Cancel      context.CancelFunc
+	baseCtx     context.Context //nolint:containedctx // parent of Context, for SetExitOnInterrupts resets.
 	fd          int
 	fdOut       int
 	oldState    *term.State
 	term        *term.Terminal
 	intrReader  *InterruptReader
+	statusW     *statusWriter
+	keys        *keyFilter // the editor's input, see ReadKey.
 	historyFile string
 	capacity    int
 	autoHistory bool
+	historyRO   bool
+	histTimes   bool // see SetHistoryTimestamps.
+	histDedup   HistoryDedup
+	hist        []histEntry    // mirror of term's history ring, oldest first, see addHistory.
+	histCount   map[string]int // occurrences of each entry in hist.
+	maxEntryLen int
+	maxHistSize int
+	pastePolicy PastePolicy
+	exitAfter   int      // see SetExitOnInterrupts.
+	interrupts  int      // consecutive interrupts count.
+	pasted      []string // pending pasted lines in PasteEditFirst mode.
+	paste       pasteState
+	completions map[completionKey]completionResult
+	complLine   string // line at the last completion call, to invalidate the cache.
+	completer   AutoCompleteCallback
+	feedMu      sync.Mutex
+	feedLines   []completionResult // pending SetLine/FeedLine edits.
+	idleTick    time.Duration
+	idleFn      func(t *Terminal)
+	search      *historySearch // Control-R search in progress, nil otherwise.
+	kills       killRing
+	bindings    map[KeySpec]KeyAction
+	multiline   *multiline // see SetMultiline, nil when off.
+	promptMu    sync.Mutex
+	promptFn    func() string
+	continuing  bool // multiline continuation prompt in use.
+	rprompt     string
+	rpActive    bool // right prompt shown (when it fits), i.e. ReadLine in progress.
+	rpLineW     int  // last known width of the edited line, for the right prompt.
+	editMode    EditMode
+	viNormal    bool // vi normal (vs insert) mode.
+	viPending   rune // pending vi operator (d).
+	prompt      string
+	autoSuggest bool
+	suggestion  string // full line for the currently shown auto suggestion.
+	password    bool   // in ReadPassword: no completion, suggestion nor vi keys.
+	width       int    // last size given to the editor, see UpdateSize.
+	height      int
+	stopResize  chan struct{}   // closed to stop the resize watcher, see SetAutoResize.
+	readCtx     context.Context //nolint:containedctx // ReadLineContext's context, nil otherwise.
+	aborted     bool            // ReadLineContext's context done and line cleared.
 }
 
+// PastePolicy controls how ReadLine handles newlines in pasted text (when bracketed paste is on).
+type PastePolicy int
+
+const (
+	// PasteAcceptAll returns each pasted line as soon as its newline is received, as if it was
+	// typed and entered (the default).
+	PasteAcceptAll PastePolicy = iota
+	// PasteEditFirst doesn't return pasted lines right away: they are accumulated until the user
+	// presses Enter (after optionally editing the last, incomplete, pasted line) and then all
+	// returned at once, separated by \n. Control-C or Control-D discard the pending pasted lines.
+	PasteEditFirst
+)
+
 // Open opens stdin as a terminal, do `defer terminal.Close()`
 // to restore the terminal to its original state upon exit.
 // fortio.org/log (and thus stdlib "log") will be redirected
@@ -40,17 +106,24 @@ type Terminal struct {
 // reading or check for done for control-c or signal.
 func Open(ctx context.Context) (t *Terminal, err error) {
 	intrReader := NewInterruptReader(os.Stdin, 256) // same as the internal x/term buffer size.
+	statusW := &statusWriter{out: os.Stderr}
+	t = &Terminal{
+		fd:          safecast.MustConvert[int](os.Stdin.Fd()),
+		fdOut:       safecast.MustConvert[int](os.Stdout.Fd()),
+		intrReader:  intrReader,
+		statusW:     statusW,
+		Context:     ctx,
+		autoHistory: true, // term's default.
+		bindings:    maps.Clone(DefaultKeyBindings),
+	}
+	t.keys = &keyFilter{t: t}
 	rw := struct {
 		io.Reader
 		io.Writer
-	}{intrReader, os.Stderr}
-	t = &Terminal{
-		fd:         safecast.MustConvert[int](os.Stdin.Fd()),
-		fdOut:      safecast.MustConvert[int](os.Stdout.Fd()),
-		intrReader: intrReader,
-		Context:    ctx,
-	}
+	}{t.keys, statusW}
 	t.term = term.NewTerminal(rw, "")
+	t.term.AutoCompleteCallback = t.autoComplete
+	t.term.AutoHistory(false) // ReadLine adds the lines, see addHistory.
 	t.Out = t.term
 	if !t.IsTerminal() {
 		t.Out = os.Stderr // no need to add \r for non raw mode.
@@ -61,7 +134,8 @@ func Open(ctx context.Context) (t *Terminal, err error) {
 	if err != nil {
 		return
 	}
-	t.term.SetBracketedPasteMode(true) // Seems useful to have it on by default.
+	t.SetBracketedPaste(true) // Seems useful to have it on by default.
+	t.SetAutoResize(true)
 	t.capacity = term.DefaultHistoryEntries
 	t.loggerSetup()
 	t.ResetInterrupts(ctx)
@@ -71,10 +145,75 @@ func Open(ctx context.Context) (t *Terminal, err error) {
 // If you want to reset and restart after an interrupt, call this.
 func (t *Terminal) ResetInterrupts(ctx context.Context) (context.Context, context.CancelFunc) {
 	// locking should not be needed as we're (supposed to be) in the main thread.
+	t.baseCtx = ctx
 	t.Context, t.Cancel = t.intrReader.Start(ctx)
 	return t.Context, t.Cancel
 }
 
+// SetBracketedPaste enables or disables bracketed paste mode (on by default after Open).
+// When off, pasted text can't be distinguished from typed text and each pasted line is
+// executed as it arrives (e.g. when driving the REPL from a script).
+func (t *Terminal) SetBracketedPaste(on bool) {
+	if !t.IsTerminal() {
+		return
+	}
+	t.term.SetBracketedPasteMode(on)
+}
+
+// SetPastePolicy sets how newlines in pasted text are handled, see [PastePolicy].
+// Only applies when bracketed paste is on.
+func (t *Terminal) SetPastePolicy(p PastePolicy) {
+	t.pastePolicy = p
+	t.pasted = nil
+}
+
+// SetInterruptByte changes (or disables) the key interrupting ReadLine, see
+// [InterruptReader.SetInterruptByte].
+func (t *Terminal) SetInterruptByte(b byte, enabled bool) {
+	t.intrReader.SetInterruptByte(b, enabled)
+}
+
+// SetInterruptSequence sets a multi keys sequence interrupting ReadLine (e.g. double Control-C),
+// see [InterruptReader.SetInterruptSequence].
+func (t *Terminal) SetInterruptSequence(seq []byte, within time.Duration) {
+	t.intrReader.SetInterruptSequence(seq, within)
+}
+
+// ErrExitRequested is returned by ReadLine for the last of the [SetExitOnInterrupts] consecutive
+// interrupts. It wraps io.EOF so loops exiting on EOF (Control-D) also exit on it.
+var ErrExitRequested = fmt.Errorf("exit requested by repeated interrupts: %w", io.EOF)
+
+// SetExitOnInterrupts makes the nth consecutive interrupt (Control-C or signal) of ReadLine
+// return [ErrExitRequested] instead of an InterruptedError, 0 (the default) to disable. The count
+// is reset when a line is read successfully. The interrupts before the nth are still returned,
+// but the interrupt handling is also reset (like with [ResetInterrupts], so t.Context and
+// t.Cancel are new ones) for the next ReadLine.
+func (t *Terminal) SetExitOnInterrupts(n int) {
+	t.exitAfter = max(n, 0)
+	t.interrupts = 0
+}
+
+// Counts interrupts for SetExitOnInterrupts, returns the error ReadLine should return.
+func (t *Terminal) countInterrupts(err error) error {
+	if t.exitAfter == 0 {
+		return err
+	}
+	if err == nil {
+		t.interrupts = 0
+		return nil
+	}
+	if !errors.As(err, &InterruptedError{}) {
+		return err
+	}
+	t.interrupts++
+	if t.interrupts >= t.exitAfter {
+		t.interrupts = 0
+		return ErrExitRequested
+	}
+	t.ResetInterrupts(t.baseCtx)
+	return err
+}
+
 func (t *Terminal) IsTerminal() bool {
 	return term.IsTerminal(t.fd)
 }
@@ -91,7 +230,8 @@ func (t *Terminal) loggerSetup() {
 }
 
 // Sets up a file to load and save history from/to. File is being read when this is called.
-// If no error is returned, the file will also be automatically updated on Close().
+// If no error is returned, the file will also be automatically updated on Close()
+// (unless [SetHistoryReadOnly] is used).
 func (t *Terminal) SetHistoryFile(f string) error {
 	if f == "" {
 		log.Infof("No history file specified")
@@ -111,24 +251,92 @@ func (t *Terminal) SetHistoryFile(f string) error {
 		t.historyFile = "" // so we don't try to save during defer'ed close if we can't read
 		return err
 	}
+	n := t.loadHistory(entries)
+	log.Infof("Loaded %d history entries from %s", n, f)
+	return nil
+}
+
+// LoadHistory seeds the history with the given entries (oldest first, like in the history file),
+// without any file I/O, for applications managing history persistence themselves.
+// If there are more entries than the capacity, only the most recent ones are kept.
+// Returns the number of entries added.
+func (t *Terminal) LoadHistory(entries []string) int {
+	h := make([]histEntry, len(entries))
+	for i, e := range entries {
+		h[i].text = e
+	}
+	return t.loadHistory(h)
+}
+
+// LoadHistory with the entries times, if any (e.g. read from the history file).
+func (t *Terminal) loadHistory(entries []histEntry) int {
+	entries = t.limitHistory(dedupEntries(entries, t.histDedup))
 	start := 0
-	if len(entries) > t.capacity {
-		log.Infof("History file %s has more than %d entries, truncating.", f, t.capacity)
+	if t.capacity > 0 && len(entries) > t.capacity {
+		log.Infof("History has more than %d entries, truncating.", t.capacity)
 		start = len(entries) - t.capacity
-	} else {
-		log.Infof("Loaded %d history entries from %s", len(entries), f)
 	}
 	for _, e := range entries[start:] {
-		t.term.AddToHistory(e)
+		t.addHistory(e.text, e.added) // dedup with the entries already present.
 	}
-	return nil
+	return len(entries) - start
+}
+
+// SaveHistory writes the current history (oldest first, in the same format as the history file)
+// to w, on demand, independently of [SetHistoryFile].
+func (t *Terminal) SaveHistory(w io.Writer) error {
+	return writeHistory(w, t.historyToSave())
+}
+
+// Returns the history oldest first, within the SetHistoryLimits limits.
+func (t *Terminal) historyToSave() []histEntry {
+	return t.limitHistory(t.hist)
+}
+
+// SetHistoryLimits sets the maximum length in bytes of a single history entry (longer ones,
+// like big pasted blobs, are dropped) and the maximum total size in bytes of the saved history
+// (oldest entries are dropped to fit). 0 means no limit (the default). Limits are enforced
+// when loading and saving history, and the entry length one also when adding entries (including
+// ReadLine's), so long entries don't stay in memory and in the up arrow history either.
+func (t *Terminal) SetHistoryLimits(maxEntryLen, maxTotalSize int) {
+	t.maxEntryLen = maxEntryLen
+	t.maxHistSize = maxTotalSize
+}
+
+// Applies the SetHistoryLimits limits to h (oldest first).
+func (t *Terminal) limitHistory(h []histEntry) []histEntry {
+	if t.maxEntryLen > 0 {
+		h = slices.DeleteFunc(slices.Clone(h), func(e histEntry) bool {
+			return len(e.text) > t.maxEntryLen
+		})
+	}
+	if t.maxHistSize <= 0 {
+		return h
+	}
+	total := 0
+	for i := len(h) - 1; i >= 0; i-- {
+		total += len(historyLines(h[i])) // same as in the file, timestamp line included.
+		if total > t.maxHistSize {
+			log.Infof("History is larger than %d bytes, dropping %d oldest entries.", t.maxHistSize, i+1)
+			return h[i+1:]
+		}
+	}
+	return h
+}
+
+// SetHistoryReadOnly makes the history file set with [SetHistoryFile] only loaded and never
+// written back on Close() (e.g. for a shared or example history).
+func (t *Terminal) SetHistoryReadOnly(readOnly bool) {
+	t.historyRO = readOnly
 }
 
 // Forward the term history API and not just the high level file history api above.
 
 // AddToHistory add commands to the history.
 func (t *Terminal) AddToHistory(commands ...string) {
-	t.term.AddToHistory(commands...)
+	for _, c := range commands {
+		t.addHistory(c, time.Now())
+	}
 }
 
 // History returns the current history state.
@@ -136,6 +344,19 @@ func (t *Terminal) History() []string {
 	return t.term.History()
 }
 
+// HistoryAll returns an iterator over the history entries, most recent first (same order as
+// [History]), for callers that only need to scan (and may stop early), without copying the
+// history. The history must not be changed (e.g. by ReadLine in another goroutine) while iterating.
+func (t *Terminal) HistoryAll() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := len(t.hist) - 1; i >= 0; i-- {
+			if !yield(t.hist[i].text) {
+				return
+			}
+		}
+	}
+}
+
 // DefaultHistoryCapacity is the default number of entries in the history (99).
 const DefaultHistoryCapacity = term.DefaultHistoryEntries
 
@@ -152,12 +373,12 @@ func (t *Terminal) NewHistory(capacity int) {
 		return
 	}
 	t.term.NewHistory(capacity)
+	t.hist, t.histCount = nil, nil
 }
 
 // SetAutoHistory enables/disables auto history (default is enabled).
 func (t *Terminal) SetAutoHistory(enabled bool) {
 	t.autoHistory = enabled
-	t.term.AutoHistory(enabled)
 }
 
 // AutoHistory returns the current auto history setting.
@@ -167,10 +388,12 @@ func (t *Terminal) AutoHistory() bool {
 
 // ReplaceLatest replaces the current history with the given commands, returns the previous value.
 func (t *Terminal) ReplaceLatest(command string) string {
-	return t.term.ReplaceLatest(command)
+	prev := t.term.ReplaceLatest(command)
+	t.replaceLatestHistory(command, time.Now())
+	return prev
 }
 
-func readOrCreateHistory(f string) ([]string, error) {
+func readOrCreateHistory(f string) ([]histEntry, error) {
 	// open file or create it
 	h, err := os.OpenFile(f, os.O_RDWR|os.O_CREATE, 0o600)
 	if err != nil {
@@ -178,29 +401,47 @@ func readOrCreateHistory(f string) ([]string, error) {
 		return nil, err
 	}
 	defer h.Close()
-	// read lines separated by \n
-	var lines []string
-	scanner := bufio.NewScanner(h)
+	entries, err := readHistory(h)
+	if err != nil {
+		log.Errf("Error reading history file %s: %v", f, err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Reads quoted lines separated by \n, each optionally preceded by a "# <unix time>" line
+// (see [SetHistoryTimestamps]). Returns the entries with their times (zero when absent).
+func readHistory(r io.Reader) ([]histEntry, error) {
+	var entries []histEntry
+	var ts time.Time
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
-		// unquote to get the actual command
 		rl := scanner.Text()
+		if sec, found := strings.CutPrefix(rl, historyTimePrefix); found {
+			v, err := strconv.ParseInt(sec, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q: %w", rl, err)
+			}
+			ts = time.Unix(v, 0)
+			continue
+		}
+		// unquote to get the actual command
 		l, err := strconv.Unquote(rl)
 		if err != nil {
-			log.Errf("Error unquoting history file %s for %q: %v", f, rl, err)
-			return nil, err
+			return nil, fmt.Errorf("error unquoting %q: %w", rl, err)
 		}
-		lines = append(lines, l)
+		entries = append(entries, histEntry{text: l, added: ts})
+		ts = time.Time{}
 	}
 	if err := scanner.Err(); err != nil {
-		log.Errf("Error reading history file %s: %v", f, err)
 		return nil, err
 	}
-	return lines, nil
+	return entries, nil
 }
 
 // We don't return any error because this is ran through a defer at the end of the program.
 // So logging errors is the best thing we can do.
-func saveHistory(f string, h []string) {
+func saveHistory(f string, h []histEntry) {
 	// open file or create it
 	hf, err := os.OpenFile(f, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0o600)
 	if err != nil {
@@ -208,14 +449,31 @@ func saveHistory(f string, h []string) {
 		return
 	}
 	defer hf.Close()
+	if err = writeHistory(hf, h); err != nil {
+		log.Errf("Error writing history file %s: %v", f, err)
+	}
+}
+
+// Writes the history, with a timestamp line before the entries whose time is known.
+func writeHistory(w io.Writer, h []histEntry) error {
 	// write lines separated by \n
-	for _, l := range h {
-		_, err := hf.WriteString(strconv.Quote(l) + "\n")
+	for _, e := range h {
+		_, err := io.WriteString(w, historyLines(e))
 		if err != nil {
-			log.Errf("Error writing history file %s: %v", f, err)
-			return
+			return err
 		}
 	}
+	return nil
+}
+
+// Returns the history file line(s) for the entry: the timestamp line, if the time is known, and
+// the quoted entry.
+func historyLines(e histEntry) string {
+	res := strconv.Quote(e.text) + "\n"
+	if !e.added.IsZero() {
+		res = historyTimePrefix + strconv.FormatInt(e.added.Unix(), 10) + "\n" + res
+	}
+	return res
 }
 
 // Temporarily suspend/resume of the terminal back to normal (for example to run a sub process).
@@ -244,11 +502,16 @@ func (t *Terminal) Resume(ctx context.Context) (context.Context, context.CancelF
 
 // Close restores the terminal to its original state. Must be called at exit to avoid leaving
 // the terminal in raw mode. Safe to call multiple times. Will save the history to the history file
-// if one was set using [SetHistoryFile] and the capacity is > 0.
+// if one was set using [SetHistoryFile], the capacity is > 0 and it isn't [SetHistoryReadOnly].
 func (t *Terminal) Close() error {
 	if t.oldState == nil {
+		if t.Cancel != nil {
+			t.Cancel() // still stop the interrupt reader when not a terminal.
+		}
 		return nil
 	}
+	t.SetAutoResize(false)
+	t.statusW.setStatus("") // erase the status line if any.
 	// To avoid prompt being repeated on the last line (shouldn't be necessary but... is
 	// consider fixing in term instead)
 	t.term.SetPrompt("") // will still reprint the last command on ^C in middle of typing.
@@ -261,37 +524,191 @@ func (t *Terminal) Close() error {
 		log.Debugf("No history file %q or capacity %d, not saving history", t.historyFile, t.capacity)
 		return nil
 	}
-	h := t.term.History()
-	// log.LogVf("got history %v", h)
-	slices.Reverse(h)
-	extra := len(h) - t.capacity
-	if extra > 0 {
-		h = h[extra:] // truncate to max capacity otherwise extra ones will get out of order
+	if t.historyRO {
+		log.Infof("History file %s is read only, not saving history", t.historyFile)
+		return err
 	}
+	h := t.historyToSave()
 	log.Infof("Saving history (%d commands) to %s", len(h), t.historyFile)
 	saveHistory(t.historyFile, h)
 	return err
 }
 
+// RestoreOnPanic closes (and thus restores) the terminal if the current goroutine is panicking
+// and then re-panics, so the panic message and stack trace are readable and the terminal isn't
+// left in raw mode. Must be deferred directly, right after Open:
+//
+//	defer t.RestoreOnPanic()
+func (t *Terminal) RestoreOnPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	_ = t.Close()
+	panic(r)
+}
+
 // ReadLine reads a line from the terminal using the setup prompt and history
 // and edit capabilities. Returns the line and an error if any. io.EOF is returned
 // when the user presses Control-D. ErrInterrupted is returned when the user presses
-// Control-C or a signal is received.
+// Control-C or a signal is received. See [SetPastePolicy] for how pasted lines are returned.
 func (t *Terminal) ReadLine() (string, error) {
-	c, err := t.term.ReadLine()
-	// That error isn't an error that needs to be propagated,
-	// it's just to allow copy/paste without autocomplete.
-	if errors.Is(err, term.ErrPasteIndicator) {
+	t.resetCompletionCache()
+	t.viStart()
+	t.RefreshPrompt()
+	t.paste.lastWasPaste = false
+	defer t.endSearch()
+	t.rpActive, t.rpLineW = true, 0
+	if t.rprompt != "" {
+		t.updateRightPrompt()
+	}
+	defer func() {
+		t.rpActive = false
+		if t.rprompt != "" {
+			t.updateRightPrompt()
+		}
+	}()
+	if t.idleFn != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go t.idleLoop(done)
+	}
+	read := t.readLine
+	if t.multiline != nil {
+		read = t.readMultiline
+	}
+	c, err := read()
+	if err == nil && t.autoHistory && t.multiline == nil { // multiline adds whole blocks.
+		t.addHistory(c, time.Now())
+	}
+	return c, t.countInterrupts(err)
+}
+
+func (t *Terminal) readLine() (string, error) {
+	for {
+		c, err := t.term.ReadLine()
+		// That error isn't an error that needs to be propagated,
+		// it's just to allow copy/paste without autocomplete.
+		if errors.Is(err, term.ErrPasteIndicator) {
+			if t.pastePolicy == PasteEditFirst {
+				t.pasted = append(t.pasted, c)
+				continue
+			}
+			t.paste.lastWasPaste = true
+			return c, nil
+		}
+		if err != nil {
+			t.pasted = nil
+			return c, err
+		}
+		if len(t.pasted) == 0 {
+			return c, nil
+		}
+		if c != "" {
+			t.pasted = append(t.pasted, c)
+		}
+		c = strings.Join(t.pasted, "\n")
+		t.pasted = nil
+		t.paste.lastWasPaste = true
 		return c, nil
 	}
+}
+
+// ReadPassword shows prompt (instead of the current one, restored after) and reads a line
+// without echoing it. The entered secret is never added to the history and isn't seen by the
+// auto completion callback. Like ReadLine, Control-C or a signal return ErrInterrupted and
+// Control-D returns io.EOF.
+func (t *Terminal) ReadPassword(prompt string) (string, error) {
+	t.password = true
+	defer func() { t.password = false }()
+	c, err := t.term.ReadPassword(prompt)
+	if errors.Is(err, term.ErrPasteIndicator) {
+		err = nil // pasting a password is fine.
+	}
 	return c, err
 }
 
-// Sets or change the prompt.
+// SetIdleTick sets a callback called every interval while ReadLine is waiting for input, for
+// instance to animate a spinner (in the status line) or poll a server. Output from fn should go
+// through t.Out, [Printf] or [SetStatusLine] so the line being edited is preserved. fn is called
+// from a separate goroutine. A nil fn or interval <= 0 disables the ticks.
+func (t *Terminal) SetIdleTick(interval time.Duration, fn func(t *Terminal)) {
+	if interval <= 0 {
+		fn = nil
+	}
+	t.idleTick = interval
+	t.idleFn = fn
+}
+
+func (t *Terminal) idleLoop(done chan struct{}) {
+	ticker := time.NewTicker(t.idleTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			t.idleFn(t)
+		}
+	}
+}
+
+// Printf writes formatted output above the prompt and the line being edited, which are then
+// redrawn. A newline is added if missing. Safe to call from other goroutines, e.g. for background
+// messages while ReadLine is in progress.
+func (t *Terminal) Printf(format string, args ...any) {
+	s := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	_, _ = io.WriteString(t.Out, s)
+}
+
+// Sets or change the prompt (removing the [SetPromptFunc] function if any).
 func (t *Terminal) SetPrompt(s string) {
+	t.promptMu.Lock()
+	t.promptFn = nil
+	t.prompt = s
+	t.promptMu.Unlock()
 	t.term.SetPrompt(s)
 }
 
+// SetPromptFunc sets a function returning the prompt, for dynamic prompts (e.g. with the time,
+// a spinner or the current directory). It is called at the start of each ReadLine and by
+// [RefreshPrompt].
+func (t *Terminal) SetPromptFunc(fn func() string) {
+	t.promptMu.Lock()
+	t.promptFn = fn
+	t.promptMu.Unlock()
+	t.RefreshPrompt()
+}
+
+// RefreshPrompt calls the [SetPromptFunc] function again and redraws the prompt (and the line
+// being edited, which isn't otherwise affected). Safe to call from other goroutines, e.g. from a
+// ticker for a live clock.
+func (t *Terminal) RefreshPrompt() {
+	t.promptMu.Lock()
+	if t.promptFn == nil {
+		t.promptMu.Unlock()
+		return
+	}
+	t.prompt = t.promptFn()
+	p, continuing := t.prompt, t.continuing
+	t.promptMu.Unlock()
+	if continuing {
+		return // the new prompt will be used after the multiline continuation lines.
+	}
+	t.term.SetPrompt(p)
+	_, _ = t.term.Write(nil) // redraws the prompt and line, if ReadLine is in progress.
+}
+
+// Returns the current prompt.
+func (t *Terminal) currentPrompt() string {
+	t.promptMu.Lock()
+	defer t.promptMu.Unlock()
+	return t.prompt
+}
+
 // AutoCompleteCallback is called with "this" terminal as first argument so AutoCompleteCallback
 // can use t.Out etc. (compared to the original x/term callback).
 type AutoCompleteCallback func(t *Terminal, line string, pos int, key rune) (newLine string, newPos int, ok bool)
@@ -299,7 +716,129 @@ type AutoCompleteCallback func(t *Terminal, line string, pos int, key rune) (new
 // SetAutoCompleteCallback sets the callback called for each key press. Can be used to implement
 // auto completion. See example/main.go for an example.
 func (t *Terminal) SetAutoCompleteCallback(f AutoCompleteCallback) {
-	t.term.AutoCompleteCallback = func(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
-		return f(t, line, pos, key)
+	t.completer = f
+}
+
+// Installed as the term callback, handles SetLine/FeedLine edits and then the user's callback if any.
+func (t *Terminal) autoComplete(line string, pos int, key rune) (newLine string, newPos int, ok bool) {
+	if t.rprompt != "" {
+		defer func() { t.rightPromptKey(line, key, newLine, ok) }()
+	}
+	if key == setLineKey {
+		t.feedMu.Lock()
+		defer t.feedMu.Unlock()
+		if len(t.feedLines) == 0 {
+			return line, pos, true // shouldn't happen, leave line as is.
+		}
+		r := t.feedLines[0]
+		t.feedLines = t.feedLines[1:]
+		return r.newLine, r.newPos, true
+	}
+	if t.password {
+		return
+	}
+	if newLine, newPos, ok = t.abortKey(line, pos, key); ok {
+		return newLine, newPos, ok
+	}
+	if key == acceptSuggestionKey {
+		s := t.suggestion
+		t.suggestion = ""
+		return s, len(s), true
+	}
+	if newLine, newPos, ok = t.multilineKey(line, pos, key); ok {
+		return newLine, newPos, ok
+	}
+	if newLine, newPos, ok = t.searchKey(line, pos, key); ok {
+		return newLine, newPos, ok
+	}
+	if t.autoSuggest {
+		defer func() { t.updateSuggestion(line, pos, key, newLine, newPos, ok) }()
+	}
+	if newLine, newPos, ok = t.killKey(line, pos, key); ok {
+		return newLine, newPos, ok
+	}
+	if t.editMode == ViMode {
+		if newLine, newPos, ok = t.viKey(line, pos, key); ok {
+			return newLine, newPos, ok
+		}
+	}
+	if t.completer == nil {
+		return
+	}
+	if t.completions == nil {
+		return t.completer(t, line, pos, key)
+	}
+	return t.cachedCompletion(t.completer, line, pos, key)
+}
+
+// Private use rune injected in the input to get the term editor to call autoComplete and
+// apply the next pending SetLine edit.
+const setLineKey = '\uf8ff'
+
+// SetLine replaces the content of the line being edited with s and moves the cursor to pos (byte
+// offset in s, clamped), for instance to pre-fill the editor for the user to confirm. Can be called
+// before ReadLine (for the next one) or while ReadLine is in progress (from another goroutine).
+func (t *Terminal) SetLine(s string, pos int) {
+	t.feedLine(s, pos, "")
+}
+
+// FeedLine submits s as if it was typed followed by Enter: the current (or next) ReadLine returns it
+// and it's added to the history like typed commands. Can be used for macros or aliases.
+func (t *Terminal) FeedLine(s string) {
+	t.feedLine(s, len(s), "\r")
+}
+
+func (t *Terminal) feedLine(s string, pos int, after string) {
+	t.feedMu.Lock()
+	t.feedLines = append(t.feedLines, completionResult{newLine: s, newPos: min(max(pos, 0), len(s))})
+	t.feedMu.Unlock()
+	t.intrReader.inject([]byte(string(setLineKey) + after))
+}
+
+type completionKey struct {
+	line string
+	pos  int
+	key  rune
+}
+
+type completionResult struct {
+	newLine string
+	newPos  int
+	ok      bool
+}
+
+// SetCompletionCache enables (or disables) caching of the auto complete callback results for
+// a given line, cursor position and key, within a single ReadLine. The cache is invalidated
+// when the line changes in a way that isn't a pure extension (e.g. backspace or history recall).
+// Useful for expensive completers; note that a cached result means the callback isn't called
+// so it shouldn't have side effects (like printing) that need repeating.
+func (t *Terminal) SetCompletionCache(enabled bool) {
+	if !enabled {
+		t.completions = nil
+		return
+	}
+	t.completions = make(map[completionKey]completionResult)
+	t.complLine = ""
+}
+
+func (t *Terminal) resetCompletionCache() {
+	if t.completions != nil {
+		clear(t.completions)
+		t.complLine = ""
+	}
+}
+
+func (t *Terminal) cachedCompletion(f AutoCompleteCallback, line string, pos int, key rune) (string, int, bool) {
+	if !strings.HasPrefix(line, t.complLine) {
+		log.LogVf("Completion cache invalidated: %q -> %q", t.complLine, line)
+		t.resetCompletionCache()
+	}
+	t.complLine = line
+	k := completionKey{line, pos, key}
+	if r, found := t.completions[k]; found {
+		return r.newLine, r.newPos, r.ok
 	}
+	newLine, newPos, ok := f(t, line, pos, key)
+	t.completions[k] = completionResult{newLine, newPos, ok}
+	return newLine, newPos, ok
 }
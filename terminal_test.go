@@ -1,6 +1,9 @@
 package terminal_test
 
 import (
+	"bytes"
+	"io"
+	"strings"
 	"testing"
 
 	"fortio.org/terminal"
@@ -15,3 +18,28 @@ func TestSetHistoryFile(t *testing.T) {
 		t.Errorf("as we run tests without terminal, history file should be ignored/no error: %v", err)
 	}
 }
+
+func TestCaptureReplay(t *testing.T) {
+	var capture bytes.Buffer
+	input := terminal.NewScriptedReader(0, []byte("ab"), []byte("\033[M *%"), []byte("\n"))
+	rec := terminal.NewInputRecorder(input, &capture)
+	all, err := io.ReadAll(rec)
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	replay, err := terminal.ParseCapture(&capture)
+	if err != nil {
+		t.Fatalf("unexpected error parsing capture %q: %v", capture.String(), err)
+	}
+	if len(replay.Chunks) != 3 || len(replay.Delays) != 3 {
+		t.Errorf("expected 3 chunks and delays, got %d, %d", len(replay.Chunks), len(replay.Delays))
+	}
+	replay.Delays = nil
+	replayed, err := io.ReadAll(replay)
+	if err != nil || !bytes.Equal(replayed, all) {
+		t.Errorf("replay mismatch %q vs %q (%v)", replayed, all, err)
+	}
+	if _, err = terminal.ParseCapture(strings.NewReader("1ms not quoted\n")); err == nil {
+		t.Errorf("expected error for invalid capture")
+	}
+}
@@ -0,0 +1,163 @@
+package terminal
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// EditMode selects the key bindings of the line editor used by ReadLine.
+type EditMode int
+
+const (
+	// EmacsMode is the default, emacs style, editing (Control-A, Control-E, etc).
+	EmacsMode EditMode = iota
+	// ViMode adds vi style normal and insert states on top of the default bindings: each
+	// ReadLine starts in insert mode, Esc switches to normal mode where h l w b 0 $ move,
+	// i a I A go back to insert mode and x dd D delete. The current mode is shown in the
+	// status line.
+	ViMode
+)
+
+const (
+	viInsertStatus = "-- INSERT --"
+	viNormalStatus = "-- NORMAL --"
+)
+
+// Private use rune replacing a standalone Esc in the input in ViMode: term otherwise waits for
+// (and swallows) the rest of what it thinks is an escape sequence.
+const viEscKey = '\uf8fe'
+
+// SetEditMode sets the line editing mode, see [ViMode].
+func (t *Terminal) SetEditMode(mode EditMode) {
+	if t.editMode == ViMode && mode != ViMode {
+		t.SetStatusLine("")
+	}
+	t.editMode = mode
+	t.viNormal = false
+	t.viPending = 0
+}
+
+// EditMode returns the current line editing mode.
+func (t *Terminal) EditMode() EditMode {
+	return t.editMode
+}
+
+func (t *Terminal) viStart() {
+	if t.editMode != ViMode {
+		return
+	}
+	t.viNormal = false
+	t.viPending = 0
+	t.SetStatusLine(viInsertStatus)
+}
+
+func (t *Terminal) viSetNormal(normal bool) {
+	t.viNormal = normal
+	t.viPending = 0
+	if normal {
+		t.SetStatusLine(viNormalStatus)
+	} else {
+		t.SetStatusLine(viInsertStatus)
+	}
+}
+
+// viKey handles key in ViMode, ok is false when the key should be processed normally
+// (i.e. inserted or passed on to the auto completion callback).
+func (t *Terminal) viKey(line string, bpos int, key rune) (newLine string, newPos int, ok bool) {
+	r := []rune(line)
+	pos := utf8.RuneCountInString(line[:bpos])
+	if !t.viNormal {
+		if key != viEscKey {
+			return
+		}
+		t.viSetNormal(true)
+		return viResult(r, pos-1)
+	}
+	if t.viPending == 'd' {
+		t.viPending = 0
+		if key == 'd' {
+			return viResult(nil, 0)
+		}
+		return viResult(r, pos) // any other key cancels the pending delete.
+	}
+	switch key {
+	case 'h':
+		pos--
+	case 'l':
+		pos++
+	case 'w':
+		pos = viNextWord(r, pos)
+	case 'b':
+		pos = viPrevWord(r, pos)
+	case '0':
+		pos = 0
+	case '$':
+		pos = len(r) - 1
+	case 'i':
+		t.viSetNormal(false)
+		return viInsertResult(r, pos)
+	case 'a':
+		t.viSetNormal(false)
+		return viInsertResult(r, pos+1)
+	case 'I':
+		t.viSetNormal(false)
+		return viInsertResult(r, 0)
+	case 'A':
+		t.viSetNormal(false)
+		return viInsertResult(r, len(r))
+	case 'x':
+		if pos < len(r) {
+			r = append(r[:pos], r[pos+1:]...)
+		}
+	case 'D':
+		r = r[:min(pos, len(r))]
+	case 'd':
+		t.viPending = 'd'
+	}
+	// Other keys are ignored in normal mode.
+	return viResult(r, pos)
+}
+
+// Returns the line and position in normal mode: the cursor stays on a character.
+func viResult(r []rune, pos int) (string, int, bool) {
+	pos = max(0, min(pos, len(r)-1))
+	return viInsertResult(r, pos)
+}
+
+func viInsertResult(r []rune, pos int) (string, int, bool) {
+	pos = max(0, min(pos, len(r)))
+	return string(r), len(string(r[:pos])), true
+}
+
+func viNextWord(r []rune, pos int) int {
+	for pos < len(r) && !unicode.IsSpace(r[pos]) {
+		pos++
+	}
+	for pos < len(r) && unicode.IsSpace(r[pos]) {
+		pos++
+	}
+	return pos
+}
+
+func viPrevWord(r []rune, pos int) int {
+	for pos > 0 && unicode.IsSpace(r[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(r[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// Replaces Esc not followed by [ or O (i.e. not the start of a special key sequence) by viEscKey.
+func translateEsc(in []byte) []byte {
+	out := make([]byte, 0, len(in))
+	for i, b := range in {
+		if b == KeyEscape && (i+1 == len(in) || (in[i+1] != '[' && in[i+1] != 'O')) {
+			out = utf8.AppendRune(out, viEscKey)
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}